@@ -0,0 +1,790 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/bulkhead"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/corpus"
+	"github.com/matthewjamesboyle/catserver/internal/credential"
+	"github.com/matthewjamesboyle/catserver/internal/daily"
+	"github.com/matthewjamesboyle/catserver/internal/favorite"
+	"github.com/matthewjamesboyle/catserver/internal/health"
+	"github.com/matthewjamesboyle/catserver/internal/httpclient"
+	"github.com/matthewjamesboyle/catserver/internal/loadshed"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"github.com/matthewjamesboyle/catserver/internal/metrics"
+	"github.com/matthewjamesboyle/catserver/internal/permalink"
+	"github.com/matthewjamesboyle/catserver/internal/quota"
+	"github.com/matthewjamesboyle/catserver/internal/ratelimit"
+	"github.com/matthewjamesboyle/catserver/internal/routing"
+	"github.com/matthewjamesboyle/catserver/internal/search"
+	"github.com/matthewjamesboyle/catserver/internal/session"
+	"github.com/matthewjamesboyle/catserver/internal/tracing"
+	"github.com/matthewjamesboyle/catserver/internal/translate"
+	"github.com/matthewjamesboyle/catserver/transport"
+	catgraphql "github.com/matthewjamesboyle/catserver/transport/graphql"
+	catgrpc "github.com/matthewjamesboyle/catserver/transport/grpc"
+	"github.com/matthewjamesboyle/catserver/transport/grpc/catpb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger, err := logging.New(os.Stdout, env("LOG_LEVEL", "info"), env("LOG_FORMAT", logging.FormatJSON))
+	if err != nil {
+		return err
+	}
+
+	creds, err := newCredentials()
+	if err != nil {
+		return err
+	}
+	if env("UPSTREAM_AUTH_FILE", "") != "" {
+		go creds.Watch(ctx.Done(), 10*time.Second, func(err error) {
+			logger.Error("reloading upstream credentials", "error", err)
+		})
+	}
+	// Nothing logged from here on can leak an upstream credential.
+	logger = slog.New(logging.NewRedactingHandler(logger.Handler(), creds))
+	slog.SetDefault(logger)
+
+	clients := httpclient.NewFactory(nil)
+	if path := env("UPSTREAM_CLIENTS_FILE", ""); path != "" {
+		if clients, err = httpclient.LoadFactory(path); err != nil {
+			return err
+		}
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	m, err := metrics.NewMetrics(reg)
+	if err != nil {
+		return err
+	}
+
+	exp, err := tracing.NewExporter(ctx, env("TRACE_EXPORTER", tracing.ExporterNone))
+	if err != nil {
+		return err
+	}
+	tp := tracing.NewProvider("catserver", exp)
+	defer func() {
+		_ = tp.Shutdown(context.Background())
+	}()
+
+	factURL := env("FACT_URL", "https://cat-fact.herokuapp.com")
+	imageURL := env("IMAGE_URL", "https://api.thecatapi.com/v1/images/search")
+
+	providers := cat.NewFactProviders()
+	if path := env("FACT_PROVIDERS_FILE", ""); path != "" {
+		if err := providers.Load(path); err != nil {
+			return err
+		}
+	}
+	factProvider, ok := providers.Get(env("FACT_PROVIDER", cat.DefaultFactProvider.Name))
+	if !ok {
+		return fmt.Errorf("unknown FACT_PROVIDER, must be one of %s", strings.Join(providers.Names(), ", "))
+	}
+
+	// With more than one upstream, each request goes to whichever is
+	// currently fastest and most reliable.
+	routers := map[string]*routing.Router{}
+	routerOpts := []routing.Option{
+		routing.WithDecay(envFloat("ROUTING_DECAY", routing.DefaultDecay)),
+		routing.WithExplore(envFloat("ROUTING_EXPLORE", routing.DefaultExplore)),
+		routing.WithErrorPenalty(envDuration("ROUTING_ERROR_PENALTY", routing.DefaultErrorPenalty)),
+	}
+	var probes []health.Probe
+	// Quota usage is saved every QUOTA_SAVE_INTERVAL and on shutdown rather
+	// than on every call.
+	var quotas []*quota.Quota
+	defer func() {
+		for _, q := range quotas {
+			if q == nil {
+				continue
+			}
+			if err := q.Save(); err != nil {
+				logger.Error("saving quota usage", "error", err)
+			}
+		}
+	}()
+
+	factUpstreams, err := upstreams("FACT_UPSTREAMS", factProvider.Name, factURL)
+	if err != nil {
+		return err
+	}
+	factGetters := map[string]cat.FactGetter{}
+	var factNames []string
+	for _, u := range factUpstreams {
+		p, ok := providers.Get(u.provider)
+		if !ok {
+			return fmt.Errorf("unknown provider %q in FACT_UPSTREAMS, must be one of %s", u.provider, strings.Join(providers.Names(), ", "))
+		}
+		name, prefixes := upstreamName("fact", u, len(factUpstreams)), envPrefixes("FACT", u, len(factUpstreams))
+		uc, err := upstreamDoers(clients, m, creds, name, true, prefixes...)
+		if err != nil {
+			return err
+		}
+		quotas = append(quotas, uc.quota)
+		fg, err := cat.NewFactService(uc.doer, u.url, cat.WithFactProvider(p))
+		if err != nil {
+			return err
+		}
+		probeURL, err := p.URL(u.url)
+		if err != nil {
+			return err
+		}
+		probeURL = env(envKey(prefixes, "_PROBE_URL"), probeURL)
+		factGetters[u.provider] = fg
+		factNames = append(factNames, u.provider)
+		probes = append(probes, health.Probe{Name: name, Group: "fact", URL: probeURL, Doer: uc.probe, Check: uc.check})
+	}
+	var fs cat.FactGetter = factGetters[factNames[0]]
+	if len(factNames) > 1 {
+		r, err := routing.NewRouter(factNames, routerOpts...)
+		if err != nil {
+			return err
+		}
+		if fs, err = routing.NewFactGetter(r, factGetters); err != nil {
+			return err
+		}
+		routers["fact"] = r
+	}
+
+	imageProviders := cat.NewImageProviders()
+	if path := env("IMAGE_PROVIDERS_FILE", ""); path != "" {
+		if err := imageProviders.Load(path); err != nil {
+			return err
+		}
+	}
+	imageProvider, ok := imageProviders.Get(env("IMAGE_PROVIDER", cat.DefaultImageProvider.Name))
+	if !ok {
+		return fmt.Errorf("unknown IMAGE_PROVIDER, must be one of %s", strings.Join(imageProviders.Names(), ", "))
+	}
+	imageUpstreams, err := upstreams("IMAGE_UPSTREAMS", imageProvider.Name, imageURL)
+	if err != nil {
+		return err
+	}
+	// Images from providers that return them inline are served at
+	// /images/{id} instead of being carried in the result.
+	imageCache, err := cat.NewImageCache(int64(envFloat("IMAGE_CACHE_BYTES", 100<<20)))
+	if err != nil {
+		return err
+	}
+	imageCacheURL := strings.TrimSuffix(env("PUBLIC_URL", ""), "/") + "/images/"
+	imageGetters := map[string]cat.ImageGetter{}
+	var imageNames []string
+	for _, u := range imageUpstreams {
+		p, ok := imageProviders.Get(u.provider)
+		if !ok {
+			return fmt.Errorf("unknown provider %q in IMAGE_UPSTREAMS, must be one of %s", u.provider, strings.Join(imageProviders.Names(), ", "))
+		}
+		name, prefixes := upstreamName("image", u, len(imageUpstreams)), envPrefixes("IMAGE", u, len(imageUpstreams))
+		// Redirect providers' targets are read from Location, never fetched.
+		uc, err := upstreamDoers(clients, m, creds, name, p.Kind != cat.ImageKindRedirect, prefixes...)
+		if err != nil {
+			return err
+		}
+		quotas = append(quotas, uc.quota)
+		ig, err := cat.NewImageService(uc.doer, u.url, cat.WithImageProvider(p), cat.WithImageCache(imageCache, imageCacheURL))
+		if err != nil {
+			return err
+		}
+		imageGetters[u.provider] = ig
+		imageNames = append(imageNames, u.provider)
+		probes = append(probes, health.Probe{Name: name, Group: "image", URL: env(envKey(prefixes, "_PROBE_URL"), u.url), Doer: uc.probe, Check: uc.check})
+	}
+	var is cat.ImageGetter = imageGetters[imageNames[0]]
+	if len(imageNames) > 1 {
+		r, err := routing.NewRouter(imageNames, routerOpts...)
+		if err != nil {
+			return err
+		}
+		if is, err = routing.NewImageGetter(r, imageGetters); err != nil {
+			return err
+		}
+		routers["image"] = r
+	}
+	facts, err := corpus.NewCorpus(env("CORPUS_FILE", ""))
+	if err != nil {
+		return err
+	}
+	defer facts.Close()
+
+	// Everything served or harvested is searchable, starting with what the
+	// corpus already holds. Served facts go into the corpus too, so they
+	// survive a restart; the harvester adds its own.
+	idx := search.NewIndex(int(envFloat("SEARCH_INDEX_MAX", 100000)))
+	for _, e := range facts.Entries() {
+		idx.Add(e.Text)
+	}
+	// Routed facts are recorded against the provider that served them.
+	recorded, err := corpus.NewFactGetter(fs, facts, factNames[0])
+	if err != nil {
+		return err
+	}
+	indexed, err := search.NewFactGetter(recorded, idx)
+	if err != nil {
+		return err
+	}
+	harvested, err := search.NewFactGetter(fs, idx)
+	if err != nil {
+		return err
+	}
+
+	history, err := session.NewMemoryHistory(envDuration("NO_REPEAT_WINDOW", time.Hour), 100000, 1000)
+	if err != nil {
+		return err
+	}
+	opts := []cat.ServiceOption{cat.WithSessionHistory(history, int(envFloat("NO_REPEAT_MAX_ATTEMPTS", cat.DefaultMaxAttempts)))}
+	translateDoer, err := clients.Doer("translate",
+		func(next cat.Doer) cat.Doer { return tracing.Doer("translate", next) },
+		func(next cat.Doer) cat.Doer { return m.Doer("translate", next) },
+		func(next cat.Doer) cat.Doer { return credential.Doer("translate", creds, next) },
+	)
+	if err != nil {
+		return err
+	}
+	translator, err := newTranslator(m, translateDoer)
+	if err != nil {
+		return err
+	}
+	if translator != nil {
+		opts = append(opts, cat.WithTranslator(translator))
+	}
+	svc, err := cat.NewService(is, indexed, opts...)
+	if err != nil {
+		return err
+	}
+
+	permalinks, err := permalink.NewBoundedStore(env("PERMALINKS_FILE", ""), int(envFloat("PERMALINKS_MAX", 100000)),
+		permalink.WithImages(imageCache, imageCacheURL))
+	if err != nil {
+		return err
+	}
+	defer permalinks.Close()
+	served, err := permalink.NewServicer(svc, permalinks, permalink.WithTranslator(svc))
+	if err != nil {
+		return err
+	}
+
+	// Breeds come from TheCatAPI by default, so they fall back to the
+	// IMAGE_* settings and share IMAGE_API_KEY.
+	breedsClient, err := upstreamDoers(clients, m, creds, "breeds", true, "BREEDS", "IMAGE")
+	if err != nil {
+		return err
+	}
+	quotas = append(quotas, breedsClient.quota)
+	breeds, err := cat.NewBreedService(breedsClient.doer, env("BREEDS_URL", "https://api.thecatapi.com/v1/breeds"), time.Hour,
+		cat.WithBreedCacheLookup(func(hit bool) { m.CacheLookup("breeds", hit) }))
+	if err != nil {
+		return err
+	}
+	for _, q := range quotas {
+		if q != nil {
+			go q.Persist(ctx.Done(), envDuration("QUOTA_SAVE_INTERVAL", 10*time.Second), func(err error) {
+				logger.Error("saving quota usage", "error", err)
+			})
+		}
+	}
+
+	h, err := transport.NewHttpHandler(served, transport.WithBreeds(breeds))
+	if err != nil {
+		return err
+	}
+	var keys *auth.FileStore
+	if path := env("API_KEYS_FILE", ""); path != "" {
+		keys, err = auth.NewFileStore(path)
+		if err != nil {
+			return err
+		}
+		go keys.Watch(ctx.Done(), 10*time.Second, func(err error) {
+			logger.Error("reloading api keys", "error", err)
+		})
+	}
+
+	var graphqlOpts []catgraphql.Option
+	if keys != nil {
+		graphqlOpts = append(graphqlOpts, catgraphql.WithBatchScope(auth.ScopeBatch))
+	}
+	gh, err := catgraphql.NewHandler(indexed, is, graphqlOpts...)
+	if err != nil {
+		return err
+	}
+	router := transport.Router(*h)
+	router.Handle("/graphql", gh).Methods(http.MethodGet, http.MethodPost)
+	router.Handle("/metrics", m.Handler()).Methods(http.MethodGet)
+	bh, err := transport.NewBreedsHandler(breeds)
+	if err != nil {
+		return err
+	}
+	router.HandleFunc("/breeds", bh.List).Methods(http.MethodGet)
+
+	var favorites favorite.FavoriteStore = favorite.NewMemoryStore()
+	if path := env("FAVORITES_FILE", ""); path != "" {
+		fstore, err := favorite.NewFileStore(path)
+		if err != nil {
+			return err
+		}
+		defer fstore.Close()
+		favorites = fstore
+	}
+	fh, err := transport.NewFavoritesHandler(favorites)
+	if err != nil {
+		return err
+	}
+	fh.Register(router)
+
+	ph, err := transport.NewPermalinkHandler(permalinks, env("PUBLIC_URL", ""), transport.WithPermalinkTranslator(svc))
+	if err != nil {
+		return err
+	}
+	router.HandleFunc("/c/{id}", ph.Get).Methods(http.MethodGet)
+
+	ih, err := transport.NewImagesHandler(imageCache, transport.WithStoredImages(permalinks))
+	if err != nil {
+		return err
+	}
+	router.HandleFunc("/images/{id}", ih.Get).Methods(http.MethodGet)
+
+	loc, err := time.LoadLocation(env("DAILY_TZ", "UTC"))
+	if err != nil {
+		return err
+	}
+	dl, err := daily.NewDaily(served, loc, env("DAILY_ARCHIVE_FILE", ""), daily.WithTranslator(svc))
+	if err != nil {
+		return err
+	}
+	dh, err := transport.NewDailyHandler(dl)
+	if err != nil {
+		return err
+	}
+	router.HandleFunc("/daily", dh.Get).Methods(http.MethodGet)
+
+	harvester, err := corpus.NewHarvester(harvested, facts, factNames[0], corpus.Budget{
+		Interval:    envDuration("HARVEST_INTERVAL", time.Minute),
+		MaxRequests: int(envFloat("HARVEST_MAX_REQUESTS", 1000)),
+		Period:      envDuration("HARVEST_PERIOD", 24*time.Hour),
+	})
+	if err != nil {
+		return err
+	}
+	ch, err := transport.NewCorpusHandler(facts)
+	if err != nil {
+		return err
+	}
+	router.HandleFunc("/facts/export", ch.Export).Methods(http.MethodGet)
+	sh, err := transport.NewSearchHandler(idx)
+	if err != nil {
+		return err
+	}
+	router.HandleFunc("/facts/search", sh.Search).Methods(http.MethodGet)
+
+	if len(routers) > 0 {
+		prh, err := transport.NewProvidersHandler(routers)
+		if err != nil {
+			return err
+		}
+		router.HandleFunc("/admin/providers", prh.Scores).Methods(http.MethodGet)
+	}
+
+	checker, err := health.NewChecker(5*time.Second, probes...)
+	if err != nil {
+		return err
+	}
+	checker.OnCacheLookup(func(hit bool) { m.CacheLookup("health", hit) })
+	router.HandleFunc("/healthz", checker.Liveness).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", checker.Readiness).Methods(http.MethodGet)
+	// ipLimit runs before auth, so floods of bad or missing keys are turned
+	// away by IP before costing a key lookup; rl then limits per key and
+	// route.
+	byIP := ratelimit.KeyByIP(int(envFloat("TRUSTED_PROXY_HOPS", 0)))
+	ipLimiter, err := ratelimit.NewLimiter(envFloat("RATE_LIMIT_IP_RPS", 20), int(envFloat("RATE_LIMIT_IP_BURST", 40)))
+	if err != nil {
+		return err
+	}
+	ipLimit, err := ratelimit.NewMiddleware(byIP, ipLimiter)
+	if err != nil {
+		return err
+	}
+	limiter, err := ratelimit.NewLimiter(envFloat("RATE_LIMIT_RPS", 5), int(envFloat("RATE_LIMIT_BURST", 10)))
+	if err != nil {
+		return err
+	}
+	rl, err := ratelimit.NewMiddleware(ratelimit.KeyByIdentity(byIP), limiter)
+	if err != nil {
+		return err
+	}
+	// Images and permalinks are fetched by browsers and unfurlers on behalf
+	// of whoever a link was shared with, so they get their own, larger
+	// buckets rather than spending the client's.
+	routeLimits, err := ratelimit.ParseRoutes("/metrics=off,/healthz=off,/readyz=off,/images/{id}=50:100,/c/{id}=20:40")
+	if err != nil {
+		return err
+	}
+	configured, err := ratelimit.ParseRoutes(env("RATE_LIMIT_ROUTES", ""))
+	if err != nil {
+		return fmt.Errorf("RATE_LIMIT_ROUTES: %w", err)
+	}
+	for tpl, l := range configured {
+		routeLimits[tpl] = l
+	}
+	for tpl, l := range routeLimits {
+		rl.Route(tpl, l)
+	}
+	for _, tpl := range []string{"/metrics", "/healthz", "/readyz"} {
+		ipLimit.Route(tpl, nil)
+	}
+
+	limit, err := loadshed.NewLimiter(loadshed.Config{
+		MinLimit: int(envFloat("LOAD_SHED_MIN_LIMIT", loadshed.DefaultMinLimit)),
+		MaxLimit: int(envFloat("LOAD_SHED_MAX_LIMIT", loadshed.DefaultMaxLimit)),
+	})
+	if err != nil {
+		return err
+	}
+	shed, err := loadshed.NewMiddleware(limit, envDuration("LOAD_SHED_RETRY_AFTER", loadshed.DefaultRetryAfter))
+	if err != nil {
+		return err
+	}
+	for _, tpl := range []string{"/healthz", "/readyz", "/metrics", "/facts/export", "/admin/providers"} {
+		shed.Exempt(tpl)
+	}
+
+	router.Use(logging.Middleware(logger), tracing.Middleware, m.Middleware, ipLimit.Handler, session.Middleware("/"), translate.Middleware)
+
+	if keys != nil {
+		am, err := auth.NewMiddleware(keys, "X-API-Key", "api_key")
+		if err != nil {
+			return err
+		}
+		am.Require("/", auth.ScopeRead)
+		am.Require("/graphql", auth.ScopeRead)
+		am.Require("/daily", auth.ScopeRead)
+		am.Require("/breeds", auth.ScopeRead)
+		am.Require("/facts/search", auth.ScopeRead)
+		am.Require("/metrics", auth.ScopeAdmin)
+		am.Require("/facts/export", auth.ScopeAdmin)
+		am.Require("/admin/providers", auth.ScopeAdmin)
+		am.Require("/favorites", auth.ScopeRead)
+		am.RequireMethod(http.MethodPost, "/favorites", auth.ScopeWrite)
+		am.RequireMethod(http.MethodDelete, "/favorites/{id}", auth.ScopeWrite)
+		router.Use(am.Handler)
+	}
+
+	// Shedding comes last, so only requests that will be served count
+	// towards the concurrency limit.
+	router.Use(rl.Handler, shed.Handler)
+
+	httpServer := &http.Server{
+		Addr:    env("HTTP_ADDR", ":8080"),
+		Handler: router,
+	}
+
+	gs, err := catgrpc.NewServer(served)
+	if err != nil {
+		return err
+	}
+	var grpcOpts []grpc.ServerOption
+	if keys != nil {
+		ga, err := catgrpc.NewAuth(keys)
+		if err != nil {
+			return err
+		}
+		ga.Require(catpb.CatService_GetImageAndFact_FullMethodName, auth.ScopeRead)
+		ga.Require(catpb.CatService_BatchGetImageAndFact_FullMethodName, auth.ScopeBatch)
+		ga.Require(catpb.CatService_StreamCats_FullMethodName, auth.ScopeBatch)
+		grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(ga.Unary()), grpc.ChainStreamInterceptor(ga.Stream()))
+	}
+	grpcServer := catgrpc.Register(gs, grpcOpts...)
+	lis, err := net.Listen("tcp", env("GRPC_ADDR", ":9090"))
+	if err != nil {
+		return err
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		logger.Info("http listening", "addr", httpServer.Addr)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	eg.Go(func() error {
+		logger.Info("grpc listening", "addr", lis.Addr().String())
+		return grpcServer.Serve(lis)
+	})
+	if env("HARVEST", "") == "true" {
+		eg.Go(func() error {
+			harvester.Run(ctx)
+			return nil
+		})
+	}
+	eg.Go(func() error {
+		<-ctx.Done()
+
+		// Fail readiness first and give load balancers time to notice
+		// before we stop accepting connections.
+		checker.Drain()
+		time.Sleep(envDuration("SHUTDOWN_DRAIN", 5*time.Second))
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		grpcServer.GracefulStop()
+		return httpServer.Shutdown(shutdownCtx)
+	})
+
+	return eg.Wait()
+}
+
+type upstream struct {
+	provider, url string
+}
+
+// upstreams parses key as a comma separated list of provider=url pairs,
+// defaulting to just the one upstream given.
+func upstreams(key, provider, url string) ([]upstream, error) {
+	v := env(key, "")
+	if v == "" {
+		return []upstream{{provider: provider, url: url}}, nil
+	}
+	var us []upstream
+	seen := map[string]bool{}
+	for _, part := range strings.Split(v, ",") {
+		name, u, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || name == "" || u == "" {
+			return nil, fmt.Errorf("%s: %q is not provider=url", key, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s: provider %q given twice", key, name)
+		}
+		seen[name] = true
+		us = append(us, upstream{provider: name, url: u})
+	}
+	return us, nil
+}
+
+// upstreamName keeps the plain upstream name for a single provider, so
+// credentials, metrics and readiness output don't change for the common
+// case. Routed providers are named kind:provider, so each gets its own.
+func upstreamName(kind string, u upstream, n int) string {
+	if n == 1 {
+		return kind
+	}
+	return kind + ":" + u.provider
+}
+
+// envPrefixes returns where to look for u's settings: a routed provider's
+// own <KIND>_<PROVIDER>_* variables first, then the <KIND>_* ones.
+func envPrefixes(kind string, u upstream, n int) []string {
+	if n == 1 {
+		return []string{kind}
+	}
+	p := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, u.provider)
+	return []string{kind + "_" + p, kind}
+}
+
+// envKey returns the first <prefix><suffix> variable that is set, or the
+// last one so callers get their fallback.
+func envKey(prefixes []string, suffix string) string {
+	for _, p := range prefixes {
+		if env(p+suffix, "") != "" {
+			return p + suffix
+		}
+	}
+	return prefixes[len(prefixes)-1] + suffix
+}
+
+// newCredentials loads upstream credentials from UPSTREAM_AUTH_FILE, or
+// takes the image API key from IMAGE_API_KEY for the common case. The key
+// only goes to IMAGE_PROVIDER and breeds, never to other routed providers.
+func newCredentials() (*credential.Store, error) {
+	if path := env("UPSTREAM_AUTH_FILE", ""); path != "" {
+		return credential.NewFileStore(path)
+	}
+	configs := map[string]credential.Config{}
+	if env("IMAGE_API_KEY", "") != "" {
+		c := credential.Config{APIKey: &credential.Secret{Env: "IMAGE_API_KEY"}}
+		configs["image"] = c
+		configs["image:"+env("IMAGE_PROVIDER", cat.DefaultImageProvider.Name)] = c
+		configs["breeds"] = c
+	}
+	return credential.NewStore(configs)
+}
+
+// upstreamClient is how one upstream is called.
+type upstreamClient struct {
+	doer, probe cat.Doer
+	// check, if set, judges readiness from real calls instead of probing.
+	check func(ctx context.Context) error
+	// quota is nil when the upstream has no rate limit or quota.
+	quota *quota.Quota
+}
+
+// upstreamDoers assembles the client for upstream from the factory's
+// *http.Client and the first of the <prefix>_* settings set, so each
+// upstream has its own credentials, bulkhead and quota. Requests pass
+// through, in order:
+// tracing, the rate limit and quota, the bulkhead, metrics and credentials.
+// Upstreams with a quota aren't probed, as probes would use it up; their
+// readiness comes from how the last real call went. Unless followRedirects,
+// redirect responses are returned rather than followed.
+func upstreamDoers(clients *httpclient.Factory, m *metrics.Metrics, creds *credential.Store, upstream string, followRedirects bool, prefixes ...string) (upstreamClient, error) {
+	b, err := newBulkhead(m, upstream, prefixes)
+	if err != nil {
+		return upstreamClient{}, err
+	}
+	c, err := clients.Client(upstream)
+	if err != nil {
+		return upstreamClient{}, err
+	}
+	if !followRedirects {
+		c = httpclient.WithoutRedirects(c)
+	}
+	base := cat.Chain(c,
+		func(next cat.Doer) cat.Doer { return bulkhead.Doer(b, next) },
+		func(next cat.Doer) cat.Doer { return m.Doer(upstream, next) },
+		func(next cat.Doer) cat.Doer { return credential.Doer(upstream, creds, next) },
+	)
+	traced := func(next cat.Doer) cat.Doer { return tracing.Doer(upstream, next) }
+
+	q, err := newQuota(upstream, prefixes)
+	if err != nil {
+		return upstreamClient{}, err
+	}
+	if q == nil {
+		d := cat.Chain(base, traced)
+		return upstreamClient{doer: d, probe: d}, nil
+	}
+	// Calls the quota refuses never reach the recorder, as they say nothing
+	// about the upstream.
+	rec := &health.Recorder{}
+	return upstreamClient{
+		doer:  cat.Chain(base, traced, func(next cat.Doer) cat.Doer { return quota.Doer(q, next) }, rec.Doer),
+		check: rec.Check,
+		quota: q,
+	}, nil
+}
+
+// newBulkhead limits concurrent calls to upstream, configured by the
+// <prefix>_BULKHEAD_* variables so each upstream is sized on its own.
+func newBulkhead(m *metrics.Metrics, upstream string, prefixes []string) (*bulkhead.Bulkhead, error) {
+	return bulkhead.NewBulkhead(bulkhead.Config{
+		MaxConcurrent: int(envFloat(envKey(prefixes, "_BULKHEAD_MAX_CONCURRENT"), 20)),
+		MaxQueue:      int(envFloat(envKey(prefixes, "_BULKHEAD_MAX_QUEUE"), 50)),
+		MaxWait:       envDuration(envKey(prefixes, "_BULKHEAD_MAX_WAIT"), time.Second),
+	}, bulkhead.Hooks{
+		Queued:   func(delta int) { m.BulkheadQueued(upstream, delta) },
+		Rejected: func() { m.BulkheadRejected(upstream) },
+	})
+}
+
+// newQuota holds calls to upstream to the <prefix>_RPS rate and the
+// <prefix>_DAILY_QUOTA and <prefix>_MONTHLY_QUOTA, or returns nil when none
+// are set. Usage is kept in QUOTA_STATE_DIR across restarts.
+func newQuota(upstream string, prefixes []string) (*quota.Quota, error) {
+	cfg := quota.Config{
+		RPS:     envFloat(envKey(prefixes, "_RPS"), 0),
+		Burst:   int(envFloat(envKey(prefixes, "_BURST"), 1)),
+		Daily:   int(envFloat(envKey(prefixes, "_DAILY_QUOTA"), 0)),
+		Monthly: int(envFloat(envKey(prefixes, "_MONTHLY_QUOTA"), 0)),
+		Pace:    env(envKey(prefixes, "_QUOTA_PACE"), "") == "true",
+		MaxWait: envDuration("QUOTA_MAX_WAIT", 2*time.Second),
+	}
+	if cfg.RPS == 0 && cfg.Daily == 0 && cfg.Monthly == 0 {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(env("QUOTA_TZ", "UTC"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Location = loc
+
+	var path string
+	if dir := env("QUOTA_STATE_DIR", ""); dir != "" {
+		path = filepath.Join(dir, strings.ReplaceAll(upstream, ":", "_")+".json")
+	}
+	return quota.NewQuota(upstream, cfg, path)
+}
+
+// newTranslator returns the configured translator, or nil when facts should
+// only be served in English. A translation service takes precedence over a
+// phrasebook.
+func newTranslator(m *metrics.Metrics, hc cat.Doer) (cat.Translator, error) {
+	var t cat.Translator
+	switch {
+	case env("TRANSLATE_URL", "") != "":
+		ht, err := translate.NewHTTPTranslator(hc, env("TRANSLATE_URL", ""), env("TRANSLATE_API_KEY", ""))
+		if err != nil {
+			return nil, err
+		}
+		t = ht
+	case env("PHRASEBOOK_FILE", "") != "":
+		p, err := translate.LoadPhrasebook(env("PHRASEBOOK_FILE", ""))
+		if err != nil {
+			return nil, err
+		}
+		t = p
+	default:
+		return nil, nil
+	}
+
+	return translate.NewCache(t, int(envFloat("TRANSLATION_CACHE_SIZE", 10000)), func(hit bool) {
+		m.CacheLookup("translation", hit)
+	})
+}
+
+func env(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envFloat(key string, fallback float64) float64 {
+	v, err := strconv.ParseFloat(env(key, ""), 64)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	v, err := time.ParseDuration(env(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return v
+}
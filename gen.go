@@ -1,4 +1,6 @@
 package gen
 
-//go:generate mockgen -package mockcat -destination internal/mock/mockcat/cat.go github.com/matthewjamesboyle/catserver/internal/cat FactGetter,ImageGetter,Doer,Servicer
-
+//go:generate mockgen -package mockcat -destination internal/mock/mockcat/cat.go github.com/matthewjamesboyle/catserver/internal/cat FactGetter,ImageGetter,Doer,Servicer,SessionHistory,Translator,BreedLister
+//go:generate mockgen -package mockauth -destination internal/mock/mockauth/auth.go github.com/matthewjamesboyle/catserver/internal/auth KeyStore
+//go:generate mockgen -package mockfavorite -destination internal/mock/mockfavorite/favorite.go github.com/matthewjamesboyle/catserver/internal/favorite FavoriteStore
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative transport/grpc/catpb/cat.proto
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/problem"
+	"net/http"
+)
+
+type identityKey struct{}
+
+// IdentityFromContext returns the caller identity stored by Middleware.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	i, ok := ctx.Value(identityKey{}).(Identity)
+	return i, ok
+}
+
+// ContextWithIdentity returns a copy of ctx carrying i.
+func ContextWithIdentity(ctx context.Context, i Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, i)
+}
+
+// ErrKeyRequired is returned by Authorize when no key was given.
+var ErrKeyRequired = errors.New("api key required")
+
+// ErrMissingScope is returned by Authorize when a valid key wasn't granted
+// the scope asked for.
+type ErrMissingScope struct {
+	Scope string
+}
+
+func (e ErrMissingScope) Error() string {
+	return fmt.Sprintf("api key lacks the %s scope", e.Scope)
+}
+
+// Authorize looks key up in store and checks it was granted scope. It is
+// shared by every transport, so keys mean the same over HTTP and gRPC.
+func Authorize(store KeyStore, key, scope string) (Identity, error) {
+	if key == "" {
+		return Identity{}, ErrKeyRequired
+	}
+	id, err := store.Lookup(key)
+	if err != nil {
+		return Identity{}, err
+	}
+	if !id.HasScope(scope) {
+		return Identity{}, ErrMissingScope{Scope: scope}
+	}
+	return id, nil
+}
+
+// Middleware authenticates requests by API key and checks the scope each
+// mux route requires.
+type Middleware struct {
+	store      KeyStore
+	header     string
+	queryParam string
+	scopes     map[string]string
+}
+
+// NewMiddleware reads the key from header, or from queryParam if the header
+// is absent. Routes without a scope set by Require are public.
+func NewMiddleware(store KeyStore, header, queryParam string) (*Middleware, error) {
+	if store == nil {
+		return nil, cat.ErrNilParam{Parameter: "store"}
+	}
+	return &Middleware{
+		store:      store,
+		header:     header,
+		queryParam: queryParam,
+		scopes:     make(map[string]string),
+	}, nil
+}
+
+// Require makes the route with path template tpl require scope.
+func (m *Middleware) Require(tpl, scope string) {
+	m.scopes[tpl] = scope
+}
+
+// RequireMethod makes requests with method to the route with path template
+// tpl require scope, in place of any scope set by Require.
+func (m *Middleware) RequireMethod(method, tpl, scope string) {
+	m.scopes[method+" "+tpl] = scope
+}
+
+// Handler is installed with mux.Router.Use.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		scope, ok := m.scopeFor(req)
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		key := req.Header.Get(m.header)
+		if key == "" && m.queryParam != "" {
+			key = req.URL.Query().Get(m.queryParam)
+		}
+
+		id, err := Authorize(m.store, key, scope)
+		var ms ErrMissingScope
+		switch {
+		case errors.Is(err, ErrKeyRequired):
+			writeProblem(w, http.StatusUnauthorized, "API key required")
+			return
+		case errors.Is(err, ErrKeyExpired):
+			writeProblem(w, http.StatusUnauthorized, "API key expired")
+			return
+		case errors.As(err, &ms):
+			writeProblem(w, http.StatusForbidden, "API key lacks the "+ms.Scope+" scope")
+			return
+		case err != nil:
+			writeProblem(w, http.StatusUnauthorized, "invalid API key")
+			return
+		}
+
+		next.ServeHTTP(w, req.WithContext(ContextWithIdentity(req.Context(), id)))
+	})
+}
+
+func (m *Middleware) scopeFor(req *http.Request) (string, bool) {
+	r := mux.CurrentRoute(req)
+	if r == nil {
+		return "", false
+	}
+	tpl, err := r.GetPathTemplate()
+	if err != nil {
+		return "", false
+	}
+	if s, ok := m.scopes[req.Method+" "+tpl]; ok {
+		return s, true
+	}
+	s, ok := m.scopes[tpl]
+	return s, ok
+}
+
+// writeProblem answers with a problem, asking for a key on a 401.
+func writeProblem(w http.ResponseWriter, code int, detail string) {
+	typ := "/problems/unauthenticated"
+	if code == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "ApiKey")
+	} else {
+		typ = "/problems/missing-scope"
+	}
+	problem.Write(w, problem.Problem{Type: typ, Status: code, Detail: detail})
+}
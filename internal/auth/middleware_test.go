@@ -0,0 +1,153 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewMiddleware(t *testing.T) {
+	m, err := auth.NewMiddleware(nil, "X-API-Key", "")
+
+	assert.Nil(t, m)
+	assert.Error(t, err)
+}
+
+func TestMiddleware_Handler(t *testing.T) {
+	newRouter := func(t *testing.T, store auth.KeyStore, got *auth.Identity) *mux.Router {
+		m, err := auth.NewMiddleware(store, "X-API-Key", "api_key")
+		require.NoError(t, err)
+		m.Require("/", auth.ScopeRead)
+		m.Require("/admin", auth.ScopeAdmin)
+		m.RequireMethod(http.MethodPost, "/", auth.ScopeWrite)
+
+		r := mux.NewRouter()
+		r.Use(m.Handler)
+		h := func(w http.ResponseWriter, req *http.Request) {
+			*got, _ = auth.IdentityFromContext(req.Context())
+		}
+		r.HandleFunc("/", h)
+		r.HandleFunc("/admin", h)
+		r.HandleFunc("/public", h)
+		return r
+	}
+
+	t.Run("Puts the identity on the context given a valid header key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		id := auth.Identity{KeyID: "k1", Scopes: []string{auth.ScopeRead}}
+		s := mockauth.NewMockKeyStore(ctrl)
+		s.EXPECT().Lookup("some-key").Return(id, nil)
+
+		var got auth.Identity
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "some-key")
+		rr := httptest.NewRecorder()
+		newRouter(t, s, &got).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, id, got)
+	})
+
+	t.Run("Accepts the key as a query parameter", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockauth.NewMockKeyStore(ctrl)
+		s.EXPECT().Lookup("some-key").Return(auth.Identity{Scopes: []string{auth.ScopeRead}}, nil)
+
+		var got auth.Identity
+		rr := httptest.NewRecorder()
+		newRouter(t, s, &got).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/?api_key=some-key", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Returns a 401 problem given no key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var got auth.Identity
+		rr := httptest.NewRecorder()
+		newRouter(t, mockauth.NewMockKeyStore(ctrl), &got).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+
+		var p map[string]interface{}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&p))
+		assert.Equal(t, float64(http.StatusUnauthorized), p["status"])
+		assert.Equal(t, "/problems/unauthenticated", p["type"])
+		assert.Equal(t, "ApiKey", rr.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("Returns a 401 given an expired key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockauth.NewMockKeyStore(ctrl)
+		s.EXPECT().Lookup("some-key").Return(auth.Identity{}, auth.ErrKeyExpired)
+
+		var got auth.Identity
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "some-key")
+		rr := httptest.NewRecorder()
+		newRouter(t, s, &got).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Returns a 403 given a key without the route's scope", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockauth.NewMockKeyStore(ctrl)
+		s.EXPECT().Lookup("some-key").Return(auth.Identity{Scopes: []string{auth.ScopeRead}}, nil)
+
+		var got auth.Identity
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("X-API-Key", "some-key")
+		rr := httptest.NewRecorder()
+		newRouter(t, s, &got).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"type":"/problems/missing-scope"`)
+	})
+
+	t.Run("Applies the scope required for the request method", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockauth.NewMockKeyStore(ctrl)
+		s.EXPECT().Lookup("some-key").Return(auth.Identity{Scopes: []string{auth.ScopeRead}}, nil).Times(2)
+
+		for method, code := range map[string]int{http.MethodGet: http.StatusOK, http.MethodPost: http.StatusForbidden} {
+			var got auth.Identity
+			req := httptest.NewRequest(method, "/", nil)
+			req.Header.Set("X-API-Key", "some-key")
+			rr := httptest.NewRecorder()
+			newRouter(t, s, &got).ServeHTTP(rr, req)
+
+			assert.Equal(t, code, rr.Code, method)
+		}
+	})
+
+	t.Run("Does not authenticate public routes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var got auth.Identity
+		rr := httptest.NewRecorder()
+		newRouter(t, mockauth.NewMockKeyStore(ctrl), &got).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/public", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
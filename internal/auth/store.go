@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"os"
+	"sync"
+	"time"
+)
+
+// Scopes a key can be granted. ScopeAdmin implies every other scope.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeBatch = "batch"
+	ScopeAdmin = "admin"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown api key")
+	ErrKeyExpired = errors.New("api key expired")
+)
+
+// Identity is the caller an API key belongs to.
+type Identity struct {
+	KeyID  string
+	Owner  string
+	Scopes []string
+}
+
+// HasScope reports whether the identity was granted scope.
+func (i Identity) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type KeyStore interface {
+	Lookup(key string) (Identity, error)
+}
+
+// Key is an entry in the key file. Only the SHA-256 of the key is stored.
+type Key struct {
+	ID        string     `json:"id"`
+	Owner     string     `json:"owner"`
+	Hash      string     `json:"hash"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// HashKey returns the hex encoded SHA-256 of key, as stored in the key file.
+func HashKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// FileStore is a KeyStore backed by a JSON array of Keys. Call Reload, or run
+// Watch, to pick up keys rotated in the file.
+type FileStore struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.RWMutex
+	keys    map[string]Key
+	modTime time.Time
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, cat.ErrNilParam{Parameter: "path"}
+	}
+	s := &FileStore{path: path, now: time.Now}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload re-reads the key file. On error the previously loaded keys are kept.
+func (s *FileStore) Reload() error {
+	fi, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("stat key file: %w", err)
+	}
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("reading key file: %w", err)
+	}
+
+	var keys []Key
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return fmt.Errorf("decoding key file: %w", err)
+	}
+
+	byHash := make(map[string]Key, len(keys))
+	for _, k := range keys {
+		if k.Hash == "" {
+			return fmt.Errorf("key %q has no hash", k.ID)
+		}
+		byHash[k.Hash] = k
+	}
+
+	s.mu.Lock()
+	s.keys = byHash
+	s.modTime = fi.ModTime()
+	s.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the key file whenever its modification time changes, until
+// done is closed. Reload errors are passed to onError.
+func (s *FileStore) Watch(done <-chan struct{}, interval time.Duration, onError func(error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			fi, err := os.Stat(s.path)
+			if err != nil {
+				onError(err)
+				continue
+			}
+			s.mu.RLock()
+			changed := !fi.ModTime().Equal(s.modTime)
+			s.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := s.Reload(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (s *FileStore) Lookup(key string) (Identity, error) {
+	s.mu.RLock()
+	k, ok := s.keys[HashKey(key)]
+	s.mu.RUnlock()
+
+	if !ok {
+		return Identity{}, ErrUnknownKey
+	}
+	if k.ExpiresAt != nil && !s.now().Before(*k.ExpiresAt) {
+		return Identity{}, ErrKeyExpired
+	}
+	return Identity{KeyID: k.ID, Owner: k.Owner, Scopes: k.Scopes}, nil
+}
@@ -0,0 +1,114 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeKeys(t *testing.T, path string, keys []auth.Key) {
+	t.Helper()
+
+	b, err := json.Marshal(keys)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, b, 0600))
+}
+
+func TestNewFileStore(t *testing.T) {
+	t.Run("Returns an error given an empty path", func(t *testing.T) {
+		s, err := auth.NewFileStore("")
+
+		assert.Nil(t, s)
+		var e cat.ErrNilParam
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, "path", e.Parameter)
+	})
+
+	t.Run("Returns an error given a missing file", func(t *testing.T) {
+		s, err := auth.NewFileStore(filepath.Join(t.TempDir(), "keys.json"))
+
+		assert.Nil(t, s)
+		assert.Error(t, err)
+	})
+
+	t.Run("Returns an error given a key without a hash", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		writeKeys(t, path, []auth.Key{{ID: "some-id"}})
+
+		s, err := auth.NewFileStore(path)
+
+		assert.Nil(t, s)
+		assert.Error(t, err)
+	})
+}
+
+func TestFileStore_Lookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	past := time.Now().Add(-time.Hour)
+	writeKeys(t, path, []auth.Key{
+		{ID: "k1", Owner: "some-owner", Hash: auth.HashKey("some-key"), Scopes: []string{auth.ScopeRead}},
+		{ID: "k2", Owner: "some-owner", Hash: auth.HashKey("old-key"), Scopes: []string{auth.ScopeRead}, ExpiresAt: &past},
+	})
+
+	s, err := auth.NewFileStore(path)
+	require.NoError(t, err)
+
+	t.Run("Returns the identity for a known key", func(t *testing.T) {
+		id, err := s.Lookup("some-key")
+
+		require.NoError(t, err)
+		assert.Equal(t, "k1", id.KeyID)
+		assert.Equal(t, "some-owner", id.Owner)
+		assert.True(t, id.HasScope(auth.ScopeRead))
+		assert.False(t, id.HasScope(auth.ScopeBatch))
+	})
+
+	t.Run("Returns ErrUnknownKey for an unknown key", func(t *testing.T) {
+		_, err := s.Lookup("some-other-key")
+
+		assert.True(t, errors.Is(err, auth.ErrUnknownKey))
+	})
+
+	t.Run("Returns ErrKeyExpired for an expired key", func(t *testing.T) {
+		_, err := s.Lookup("old-key")
+
+		assert.True(t, errors.Is(err, auth.ErrKeyExpired))
+	})
+}
+
+func TestFileStore_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys(t, path, []auth.Key{{ID: "k1", Hash: auth.HashKey("some-key")}})
+
+	s, err := auth.NewFileStore(path)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	defer close(done)
+	go s.Watch(done, 10*time.Millisecond, func(err error) { t.Error(err) })
+
+	writeKeys(t, path, []auth.Key{{ID: "k2", Hash: auth.HashKey("new-key")}})
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+	assert.Eventually(t, func() bool {
+		_, err := s.Lookup("new-key")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = s.Lookup("some-key")
+	assert.True(t, errors.Is(err, auth.ErrUnknownKey))
+}
+
+func TestIdentity_HasScope(t *testing.T) {
+	id := auth.Identity{Scopes: []string{auth.ScopeAdmin}}
+
+	assert.True(t, id.HasScope(auth.ScopeRead))
+	assert.True(t, id.HasScope(auth.ScopeBatch))
+}
@@ -0,0 +1,137 @@
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBulkheadFull is returned when every slot is taken and the wait queue is
+// full, or a queued request waited longer than MaxWait.
+var ErrBulkheadFull = errors.New("bulkhead full")
+
+// Config sizes a Bulkhead.
+type Config struct {
+	// MaxConcurrent is how many requests may be in flight at once.
+	MaxConcurrent int
+	// MaxQueue is how many more may wait for a slot. Zero fails fast as
+	// soon as every slot is taken.
+	MaxQueue int
+	// MaxWait bounds how long a request waits in the queue. Zero waits
+	// until the request's context is done.
+	MaxWait time.Duration
+}
+
+// Hooks are told about a Bulkhead's activity, e.g. to report it in metrics.
+// Either may be nil.
+type Hooks struct {
+	// Queued is called with +1 when a request starts waiting and -1 when it
+	// stops.
+	Queued func(delta int)
+	// Rejected is called each time ErrBulkheadFull is returned.
+	Rejected func()
+}
+
+// Bulkhead limits how many requests to one upstream are in flight, so that a
+// slow upstream can't take every goroutine and connection for itself.
+type Bulkhead struct {
+	cfg   Config
+	hooks Hooks
+	slots chan struct{}
+
+	mu     sync.Mutex
+	queued int
+}
+
+func NewBulkhead(cfg Config, hooks Hooks) (*Bulkhead, error) {
+	if cfg.MaxConcurrent < 1 {
+		return nil, errors.New("MaxConcurrent must be at least 1")
+	}
+	if cfg.MaxQueue < 0 {
+		return nil, errors.New("MaxQueue must not be negative")
+	}
+	if cfg.MaxWait < 0 {
+		return nil, errors.New("MaxWait must not be negative")
+	}
+	return &Bulkhead{cfg: cfg, hooks: hooks, slots: make(chan struct{}, cfg.MaxConcurrent)}, nil
+}
+
+// Acquire takes a slot, waiting in the queue if there's room. The returned
+// func gives the slot back and must be called exactly once.
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	default:
+	}
+
+	if !b.enqueue() {
+		b.reject()
+		return nil, ErrBulkheadFull
+	}
+	defer b.dequeue()
+
+	var timeout <-chan time.Time
+	if b.cfg.MaxWait > 0 {
+		t := time.NewTimer(b.cfg.MaxWait)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case b.slots <- struct{}{}:
+		return b.release, nil
+	case <-timeout:
+		b.reject()
+		return nil, ErrBulkheadFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// InFlight is the number of slots taken.
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+// Queued is the number of requests waiting for a slot.
+func (b *Bulkhead) Queued() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.queued
+}
+
+func (b *Bulkhead) release() {
+	<-b.slots
+}
+
+func (b *Bulkhead) enqueue() bool {
+	b.mu.Lock()
+	if b.queued >= b.cfg.MaxQueue {
+		b.mu.Unlock()
+		return false
+	}
+	b.queued++
+	b.mu.Unlock()
+
+	if b.hooks.Queued != nil {
+		b.hooks.Queued(1)
+	}
+	return true
+}
+
+func (b *Bulkhead) dequeue() {
+	b.mu.Lock()
+	b.queued--
+	b.mu.Unlock()
+
+	if b.hooks.Queued != nil {
+		b.hooks.Queued(-1)
+	}
+}
+
+func (b *Bulkhead) reject() {
+	if b.hooks.Rejected != nil {
+		b.hooks.Rejected()
+	}
+}
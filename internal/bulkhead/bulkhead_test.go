@@ -0,0 +1,86 @@
+package bulkhead_test
+
+import (
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/bulkhead"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNewBulkhead(t *testing.T) {
+	for _, cfg := range []bulkhead.Config{
+		{MaxConcurrent: 0},
+		{MaxConcurrent: 1, MaxQueue: -1},
+		{MaxConcurrent: 1, MaxWait: -time.Second},
+	} {
+		b, err := bulkhead.NewBulkhead(cfg, bulkhead.Hooks{})
+		assert.Nil(t, b)
+		assert.Error(t, err)
+	}
+}
+
+func TestBulkhead_Acquire(t *testing.T) {
+	t.Run("Fails fast once every slot is taken and there's no queue", func(t *testing.T) {
+		var rejected int
+		b, err := bulkhead.NewBulkhead(bulkhead.Config{MaxConcurrent: 2}, bulkhead.Hooks{Rejected: func() { rejected++ }})
+		require.NoError(t, err)
+
+		r1, err := b.Acquire(context.Background())
+		require.NoError(t, err)
+		_, err = b.Acquire(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, b.InFlight())
+
+		_, err = b.Acquire(context.Background())
+		assert.True(t, errors.Is(err, bulkhead.ErrBulkheadFull))
+		assert.Equal(t, 1, rejected)
+
+		r1()
+		_, err = b.Acquire(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Queued requests get the next free slot", func(t *testing.T) {
+		queued := make(chan int, 10)
+		b, err := bulkhead.NewBulkhead(bulkhead.Config{MaxConcurrent: 1, MaxQueue: 1}, bulkhead.Hooks{Queued: func(d int) { queued <- d }})
+		require.NoError(t, err)
+
+		release, err := b.Acquire(context.Background())
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			_, err := b.Acquire(context.Background())
+			done <- err
+		}()
+		assert.Equal(t, 1, <-queued)
+		assert.Equal(t, 1, b.Queued())
+
+		_, err = b.Acquire(context.Background())
+		assert.True(t, errors.Is(err, bulkhead.ErrBulkheadFull), "the queue is full")
+
+		release()
+		assert.NoError(t, <-done)
+		assert.Equal(t, -1, <-queued)
+		assert.Equal(t, 0, b.Queued())
+	})
+
+	t.Run("Gives up after MaxWait or when the context is done", func(t *testing.T) {
+		b, err := bulkhead.NewBulkhead(bulkhead.Config{MaxConcurrent: 1, MaxQueue: 5, MaxWait: 10 * time.Millisecond}, bulkhead.Hooks{})
+		require.NoError(t, err)
+		_, err = b.Acquire(context.Background())
+		require.NoError(t, err)
+
+		_, err = b.Acquire(context.Background())
+		assert.True(t, errors.Is(err, bulkhead.ErrBulkheadFull))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = b.Acquire(ctx)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 0, b.Queued())
+	})
+}
@@ -0,0 +1,46 @@
+package bulkhead
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type doer struct {
+	b    *Bulkhead
+	next cat.Doer
+}
+
+// Doer wraps next so that every call holds a slot in b. The slot is held
+// until the response body is closed, since the connection is busy until
+// then, so callers must always close it.
+func Doer(b *Bulkhead, next cat.Doer) cat.Doer {
+	return &doer{b: b, next: next}
+}
+
+func (d *doer) Do(req *http.Request) (*http.Response, error) {
+	release, err := d.b.Acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	res, err := d.next.Do(req)
+	if err != nil || res == nil || res.Body == nil {
+		release()
+		return res, err
+	}
+	res.Body = &releasingBody{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,60 @@
+package bulkhead_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/bulkhead"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestDoer(t *testing.T) {
+	t.Run("Holds the slot until the body is closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		b, err := bulkhead.NewBulkhead(bulkhead.Config{MaxConcurrent: 1}, bulkhead.Hooks{})
+		require.NoError(t, err)
+		md := mockcat.NewMockDoer(ctrl)
+		md.EXPECT().Do(gomock.Any()).DoAndReturn(func(*http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+		}).Times(2)
+		d := bulkhead.Doer(b, md)
+		req, err := http.NewRequest(http.MethodGet, "http://some-url", nil)
+		require.NoError(t, err)
+
+		res, err := d.Do(req)
+		require.NoError(t, err)
+		_, err = d.Do(req)
+		assert.True(t, errors.Is(err, bulkhead.ErrBulkheadFull))
+
+		require.NoError(t, res.Body.Close())
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, 0, b.InFlight())
+
+		res, err = d.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("Releases the slot when the call fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		b, err := bulkhead.NewBulkhead(bulkhead.Config{MaxConcurrent: 1}, bulkhead.Hooks{})
+		require.NoError(t, err)
+		md := mockcat.NewMockDoer(ctrl)
+		md.EXPECT().Do(gomock.Any()).Return(nil, errors.New("some-error"))
+		req, err := http.NewRequest(http.MethodGet, "http://some-url", nil)
+		require.NoError(t, err)
+
+		_, err = bulkhead.Doer(b, md).Do(req)
+		assert.Error(t, err)
+		assert.Equal(t, 0, b.InFlight())
+	})
+}
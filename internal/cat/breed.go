@@ -0,0 +1,144 @@
+package cat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/sync/singleflight"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreedLister lists the breeds the image upstream can filter by.
+type BreedLister interface {
+	ListBreeds(ctx context.Context) ([]Breed, error)
+}
+
+// Bounds on how long BreedService waits to retry after a failed refresh,
+// doubling with each failure in a row.
+const (
+	minBreedRetry = 5 * time.Second
+	maxBreedRetry = 5 * time.Minute
+)
+
+// BreedService fetches the breed list from the image upstream and keeps it
+// for ttl. If a refresh fails, the retries back off, and until one
+// succeeds the previous list is served, or the last error if there is none.
+type BreedService struct {
+	hc       Doer
+	url      string
+	ttl      time.Duration
+	onLookup func(hit bool)
+	group    singleflight.Group
+
+	mu        sync.Mutex
+	breeds    []Breed
+	fetchedAt time.Time
+	failures  int
+	retryAt   time.Time
+	lastErr   error
+}
+
+type BreedServiceOption func(s *BreedService)
+
+// WithBreedCacheLookup calls onLookup on every ListBreeds, with whether the
+// cached list was fresh enough to serve without calling the upstream.
+func WithBreedCacheLookup(onLookup func(hit bool)) BreedServiceOption {
+	return func(s *BreedService) {
+		s.onLookup = onLookup
+	}
+}
+
+func NewBreedService(hc Doer, url string, ttl time.Duration, opts ...BreedServiceOption) (*BreedService, error) {
+	if hc == nil {
+		return nil, ErrNilParam{Parameter: "Doer"}
+	}
+	if url == "" {
+		return nil, ErrNilParam{Parameter: "url"}
+	}
+	s := &BreedService{hc: hc, url: url, ttl: ttl, onLookup: func(bool) {}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+func (s *BreedService) ListBreeds(ctx context.Context) ([]Breed, error) {
+	now := time.Now()
+	s.mu.Lock()
+	breeds := s.breeds
+	fresh := breeds != nil && now.Sub(s.fetchedAt) < s.ttl
+	backingOff := now.Before(s.retryAt)
+	lastErr := s.lastErr
+	s.mu.Unlock()
+
+	s.onLookup(fresh)
+	switch {
+	case fresh, backingOff && breeds != nil:
+		return breeds, nil
+	case backingOff:
+		return nil, lastErr
+	}
+
+	// Concurrent callers share one fetch, which outlives any one of them
+	// giving up.
+	v, err, _ := s.group.Do("breeds", func() (interface{}, error) {
+		return s.refresh(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		if breeds != nil {
+			return breeds, nil
+		}
+		return nil, err
+	}
+	return v.([]Breed), nil
+}
+
+// refresh fetches the list and records the outcome.
+func (s *BreedService) refresh(ctx context.Context) ([]Breed, error) {
+	breeds, err := s.fetch(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failures++
+		retry := minBreedRetry << min(s.failures-1, 6)
+		s.retryAt = time.Now().Add(min(retry, maxBreedRetry))
+		s.lastErr = err
+		return nil, err
+	}
+	s.breeds = breeds
+	s.fetchedAt = time.Now()
+	s.failures = 0
+	s.retryAt = time.Time{}
+	s.lastErr = nil
+	return breeds, nil
+}
+
+func (s *BreedService) fetch(ctx context.Context) ([]Breed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	start := time.Now()
+	res, err := s.hc.Do(req)
+	if err != nil {
+		logUpstreamFailure(ctx, "breeds", start, nil, err)
+		return nil, fmt.Errorf("calling breed service: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		err := fmt.Errorf("breed service returned %d", res.StatusCode)
+		logUpstreamFailure(ctx, "breeds", start, res, err)
+		return nil, err
+	}
+
+	breeds := []Breed{}
+	if err := json.NewDecoder(res.Body).Decode(&breeds); err != nil {
+		logUpstreamFailure(ctx, "breeds", start, res, err)
+		return nil, fmt.Errorf("unmarshall Response: %w", err)
+	}
+	return breeds, nil
+}
@@ -0,0 +1,122 @@
+package cat_test
+
+import (
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewBreedService(t *testing.T) {
+	_, err := cat.NewBreedService(nil, "some-url", time.Hour)
+	var e cat.ErrNilParam
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "Doer", e.Parameter)
+
+	_, err = cat.NewBreedService(http.DefaultClient, "", time.Hour)
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "url", e.Parameter)
+}
+
+func TestBreedService_ListBreeds(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": "beng", "name": "Bengal", "origin": "United States", "life_span": "12 - 15"}]`))
+	}))
+	defer srv.Close()
+
+	t.Run("Caches the list for the ttl", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		var lookups []bool
+		s, err := cat.NewBreedService(srv.Client(), srv.URL, time.Hour, cat.WithBreedCacheLookup(func(hit bool) {
+			lookups = append(lookups, hit)
+		}))
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			b, err := s.ListBreeds(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []cat.Breed{{ID: "beng", Name: "Bengal", Origin: "United States"}}, b)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Equal(t, []bool{false, true, true}, lookups)
+	})
+
+	t.Run("Serves the stale list when a refresh fails", func(t *testing.T) {
+		failing.Store(false)
+		s, err := cat.NewBreedService(srv.Client(), srv.URL, 0)
+		require.NoError(t, err)
+
+		_, err = s.ListBreeds(ctx)
+		require.NoError(t, err)
+
+		failing.Store(true)
+		atomic.StoreInt32(&calls, 0)
+		b, err := s.ListBreeds(ctx)
+		require.NoError(t, err)
+		assert.Len(t, b, 1)
+
+		b, err = s.ListBreeds(ctx)
+		require.NoError(t, err)
+		assert.Len(t, b, 1)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "backs off before retrying")
+	})
+
+	t.Run("Shares one fetch between concurrent callers", func(t *testing.T) {
+		var slowCalls int32
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&slowCalls, 1)
+			<-release
+			_, _ = w.Write([]byte(`[{"id": "beng", "name": "Bengal"}]`))
+		}))
+		defer slow.Close()
+
+		s, err := cat.NewBreedService(slow.Client(), slow.URL, time.Hour)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := s.ListBreeds(ctx)
+				assert.NoError(t, err)
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&slowCalls))
+	})
+
+	t.Run("Returns an error with nothing to fall back on", func(t *testing.T) {
+		failing.Store(true)
+		s, err := cat.NewBreedService(srv.Client(), srv.URL, time.Hour)
+		require.NoError(t, err)
+
+		atomic.StoreInt32(&calls, 0)
+		_, err = s.ListBreeds(ctx)
+		assert.EqualError(t, err, "breed service returned 502")
+
+		_, err = s.ListBreeds(ctx)
+		assert.EqualError(t, err, "breed service returned 502")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "backs off before retrying on a cold start too")
+	})
+}
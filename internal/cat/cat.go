@@ -3,12 +3,25 @@ package cat
 import (
 	"context"
 	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
+	"time"
 )
 
+var tracer = otel.Tracer("github.com/matthewjamesboyle/catserver/internal/cat")
+
 type CatResult struct {
+	// ID is a short permalink ID, set when results are stored for sharing.
+	ID string `json:",omitempty"`
+	// ImageURL is Image.URL, kept for clients that predate Image.
 	ImageURL ImageURL
+	Image    *Image `json:",omitempty"`
 	Fact     Fact
+	// Translation is Fact in the caller's language, when they asked for one
+	// other than SourceLanguage and it could be translated.
+	Translation *Translation `json:",omitempty"`
 }
 
 type Servicer interface {
@@ -18,6 +31,10 @@ type Servicer interface {
 type Service struct {
 	img  ImageGetter
 	fact FactGetter
+
+	history     SessionHistory
+	maxAttempts int
+	translator  Translator
 }
 
 type ErrNilParam struct {
@@ -32,7 +49,29 @@ type ErrServiceError struct {
 	UnderLyingError error
 }
 
-func NewService(getter ImageGetter, factGetter FactGetter) (*Service, error) {
+func (e ErrServiceError) Error() string {
+	return fmt.Sprintf("service error: %v", e.UnderLyingError)
+}
+
+func (e ErrServiceError) Unwrap() error {
+	return e.UnderLyingError
+}
+
+// ErrQuotaExhausted is returned when calling upstream would go over its
+// rate limit or quota for Period ("second", "day" or "month"). It is worth
+// falling back to cached or local results rather than failing.
+type ErrQuotaExhausted struct {
+	Upstream string
+	Period   string
+	// RetryAfter is how long until upstream can be called again.
+	RetryAfter time.Duration
+}
+
+func (e ErrQuotaExhausted) Error() string {
+	return fmt.Sprintf("%s quota for the %s exhausted, retry after %s", e.Upstream, e.Period, e.RetryAfter)
+}
+
+func NewService(getter ImageGetter, factGetter FactGetter, opts ...ServiceOption) (*Service, error) {
 	if getter == nil {
 		return nil, ErrNilParam{Parameter: "ImageGetter"}
 	}
@@ -40,42 +79,100 @@ func NewService(getter ImageGetter, factGetter FactGetter) (*Service, error) {
 		return nil, ErrNilParam{Parameter: "FactGetter"}
 	}
 
-	return &Service{
-		img:  getter,
-		fact: factGetter,
-	}, nil
+	s := &Service{
+		img:         getter,
+		fact:        factGetter,
+		maxAttempts: DefaultMaxAttempts,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.maxAttempts < 1 {
+		s.maxAttempts = 1
+	}
+	return s, nil
 }
 
 func (s *Service) GetImageAndFact(ctx context.Context) (CatResult, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetImageAndFact")
+	defer span.End()
 
-	eg, ctx := errgroup.WithContext(ctx)
+	session := SessionFromContext(ctx)
+	if s.history == nil {
+		session = ""
+	}
 
 	var f Fact
-	var i ImageURL
-	eg.Go(func() error {
-		ft, err := s.fact.GetFact(ctx)
-		f = ft
+	var i Image
+	needFact, needImage := true, true
+	for attempt := 0; attempt < s.maxAttempts && (needFact || needImage); attempt++ {
+		ft, it, err := s.fetch(ctx, needFact, needImage)
 		if err != nil {
-			return fmt.Errorf("GetImageAndFact GetFact: %w", err)
+			recordError(span, err)
+			return CatResult{}, ErrServiceError{UnderLyingError: err}
 		}
-		return nil
-	})
-
-	eg.Go(func() error {
-		it, err := s.img.GetImage(ctx)
-		i = it
-		if err != nil {
-			return fmt.Errorf("GetImageAndFact GetImage: %w", err)
+		if needFact {
+			f = ft
+			needFact = session != "" && !s.history.Claim(session, factKey(f))
+		}
+		if needImage {
+			i = it
+			needImage = session != "" && !s.history.Claim(session, imageKey(i.URL))
 		}
-		return nil
-	})
-
-	if err := eg.Wait(); err != nil {
-		return CatResult{}, err
 	}
+	// Having run out of attempts, a repeat beats failing the request.
 
 	return CatResult{
-		ImageURL: i,
-		Fact:     f,
+		ImageURL:    i.URL,
+		Image:       &i,
+		Fact:        f,
+		Translation: s.translate(ctx, f),
 	}, nil
 }
+
+// fetch gets whichever of the fact and image are asked for, concurrently.
+func (s *Service) fetch(ctx context.Context, fact, image bool) (Fact, Image, error) {
+	eg, ctx := errgroup.WithContext(ctx)
+
+	var f Fact
+	var i Image
+	if fact {
+		eg.Go(func() error {
+			ctx, span := tracer.Start(ctx, "GetFact")
+			defer span.End()
+
+			ft, err := s.fact.GetFact(ctx)
+			f = ft
+			if err != nil {
+				recordError(span, err)
+				return fmt.Errorf("GetImageAndFact GetFact: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if image {
+		eg.Go(func() error {
+			ctx, span := tracer.Start(ctx, "GetImage")
+			defer span.End()
+
+			it, err := s.img.GetImage(ctx)
+			i = it
+			if err != nil {
+				recordError(span, err)
+				return fmt.Errorf("GetImageAndFact GetImage: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return "", Image{}, err
+	}
+	return f, i, nil
+}
+
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"testing"
 )
 
@@ -70,16 +73,16 @@ func TestService_GetImageAndFact(t *testing.T) {
 		someImage := cat.ImageURL("some-image-url")
 		someFact := cat.Fact("some-fact")
 		ctx := context.Background()
-		ctxWc, _ := context.WithCancel(ctx)
 
-		f.EXPECT().GetFact(ctxWc).Return(someFact, nil)
-		g.EXPECT().GetImage(ctxWc).Return(someImage, nil)
+		f.EXPECT().GetFact(gomock.Any()).Return(someFact, nil)
+		g.EXPECT().GetImage(gomock.Any()).Return(cat.Image{URL: someImage}, nil)
 
 		c, err := s.GetImageAndFact(ctx)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, c)
 		assert.Equal(t, someImage, c.ImageURL)
+		assert.Equal(t, &cat.Image{URL: someImage}, c.Image)
 		assert.Equal(t, someFact, c.Fact)
 
 	})
@@ -92,16 +95,17 @@ func TestService_GetImageAndFact(t *testing.T) {
 		g := mockcat.NewMockImageGetter(ctrl)
 		s, err := cat.NewService(g, f)
 		testErr := errors.New("some-error")
-		ctx := context.Background()
-		ctxWc, _ := context.WithCancel(ctx)
 
-		f.EXPECT().GetFact(ctxWc).Return(cat.Fact(""), testErr)
-		g.EXPECT().GetImage(ctxWc).Times(1)
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact(""), testErr)
+		g.EXPECT().GetImage(gomock.Any()).Times(1)
 
 		c, err := s.GetImageAndFact(context.Background())
 		require.Equal(t, c, cat.CatResult{})
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, testErr))
+
+		var se cat.ErrServiceError
+		assert.True(t, errors.As(err, &se))
 	})
 
 	t.Run("Returns an error given FactGetter succeeds but imageGetter fails", func(t *testing.T) {
@@ -114,7 +118,7 @@ func TestService_GetImageAndFact(t *testing.T) {
 		testErr := errors.New("some-error")
 		ctx := context.Background()
 		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("some-fact"), nil)
-		g.EXPECT().GetImage(gomock.Any()).Return(cat.ImageURL(""), testErr)
+		g.EXPECT().GetImage(gomock.Any()).Return(cat.Image{}, testErr)
 
 		c, err := s.GetImageAndFact(ctx)
 
@@ -123,4 +127,170 @@ func TestService_GetImageAndFact(t *testing.T) {
 		assert.True(t, errors.Is(err, testErr))
 
 	})
+
+	t.Run("Records a span for the service and each upstream branch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		exp := tracetest.NewInMemoryExporter()
+		prev := otel.GetTracerProvider()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+		otel.SetTracerProvider(tp)
+		defer otel.SetTracerProvider(prev)
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		g := mockcat.NewMockImageGetter(ctrl)
+		s, err := cat.NewService(g, f)
+		require.NoError(t, err)
+
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("some-fact"), nil)
+		g.EXPECT().GetImage(gomock.Any()).Return(cat.Image{}, errors.New("some-error"))
+
+		_, err = s.GetImageAndFact(context.Background())
+		require.Error(t, err)
+
+		spans := exp.GetSpans()
+		require.Len(t, spans, 3)
+		byName := map[string]tracetest.SpanStub{}
+		for _, sp := range spans {
+			byName[sp.Name] = sp
+		}
+		root := byName["Service.GetImageAndFact"]
+		require.True(t, root.SpanContext.IsValid())
+		assert.Equal(t, root.SpanContext.SpanID(), byName["GetFact"].Parent.SpanID())
+		assert.Equal(t, root.SpanContext.SpanID(), byName["GetImage"].Parent.SpanID())
+		assert.Len(t, byName["GetImage"].Events, 1)
+	})
+
+	t.Run("Re-fetches only the part the session has already seen", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		g := mockcat.NewMockImageGetter(ctrl)
+		h := mockcat.NewMockSessionHistory(ctrl)
+		s, err := cat.NewService(g, f, cat.WithSessionHistory(h, 3))
+		require.NoError(t, err)
+
+		gomock.InOrder(
+			f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("old-fact"), nil),
+			f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("new-fact"), nil),
+		)
+		g.EXPECT().GetImage(gomock.Any()).Return(cat.Image{URL: "new-image"}, nil).Times(1)
+		h.EXPECT().Claim("some-session", "fact:old-fact").Return(false)
+		h.EXPECT().Claim("some-session", "fact:new-fact").Return(true)
+		h.EXPECT().Claim("some-session", "image:new-image").Return(true)
+
+		c, err := s.GetImageAndFact(cat.WithSession(context.Background(), "some-session"))
+
+		require.NoError(t, err)
+		assert.Equal(t, cat.Fact("new-fact"), c.Fact)
+		assert.Equal(t, cat.ImageURL("new-image"), c.ImageURL)
+	})
+
+	t.Run("Serves a repeat once maxAttempts is reached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		g := mockcat.NewMockImageGetter(ctrl)
+		h := mockcat.NewMockSessionHistory(ctrl)
+		s, err := cat.NewService(g, f, cat.WithSessionHistory(h, 2))
+		require.NoError(t, err)
+
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("old-fact"), nil).Times(2)
+		g.EXPECT().GetImage(gomock.Any()).Return(cat.Image{URL: "new-image"}, nil).Times(1)
+		h.EXPECT().Claim("some-session", "fact:old-fact").Return(false).Times(2)
+		h.EXPECT().Claim("some-session", "image:new-image").Return(true)
+
+		c, err := s.GetImageAndFact(cat.WithSession(context.Background(), "some-session"))
+
+		require.NoError(t, err)
+		assert.Equal(t, cat.Fact("old-fact"), c.Fact)
+	})
+
+	t.Run("Ignores the history without a session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		g := mockcat.NewMockImageGetter(ctrl)
+		s, err := cat.NewService(g, f, cat.WithSessionHistory(mockcat.NewMockSessionHistory(ctrl), 3))
+		require.NoError(t, err)
+
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("some-fact"), nil)
+		g.EXPECT().GetImage(gomock.Any()).Return(cat.Image{URL: "some-image"}, nil)
+
+		_, err = s.GetImageAndFact(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Translates into the first supported language", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		g := mockcat.NewMockImageGetter(ctrl)
+		tr := mockcat.NewMockTranslator(ctrl)
+		s, err := cat.NewService(g, f, cat.WithTranslator(tr))
+		require.NoError(t, err)
+
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("Cats purr."), nil)
+		g.EXPECT().GetImage(gomock.Any()).Return(cat.Image{URL: "some-image"}, nil)
+		gomock.InOrder(
+			tr.EXPECT().Translate(gomock.Any(), cat.Fact("Cats purr."), "xx").Return(cat.Fact(""), cat.ErrUnsupportedLanguage),
+			tr.EXPECT().Translate(gomock.Any(), cat.Fact("Cats purr."), "de").Return(cat.Fact("Katzen schnurren."), nil),
+		)
+
+		res, err := s.GetImageAndFact(cat.WithLanguages(context.Background(), "xx", "de", "fr"))
+		require.NoError(t, err)
+		assert.Equal(t, cat.Fact("Cats purr."), res.Fact)
+		assert.Equal(t, &cat.Translation{Lang: "de", Fact: "Katzen schnurren."}, res.Translation)
+	})
+
+	t.Run("Serves the original when English is preferred or translation fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		g := mockcat.NewMockImageGetter(ctrl)
+		tr := mockcat.NewMockTranslator(ctrl)
+		s, err := cat.NewService(g, f, cat.WithTranslator(tr))
+		require.NoError(t, err)
+
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("Cats purr."), nil).Times(3)
+		g.EXPECT().GetImage(gomock.Any()).Return(cat.Image{URL: "some-image"}, nil).Times(3)
+		tr.EXPECT().Translate(gomock.Any(), gomock.Any(), "fr").Return(cat.Fact(""), errors.New("boom"))
+
+		res, err := s.GetImageAndFact(cat.WithLanguages(context.Background(), "en", "de"))
+		require.NoError(t, err)
+		assert.Nil(t, res.Translation)
+
+		res, err = s.GetImageAndFact(cat.WithLanguages(context.Background(), "en-GB", "de"))
+		require.NoError(t, err)
+		assert.Nil(t, res.Translation)
+
+		res, err = s.GetImageAndFact(cat.WithLanguages(context.Background(), "fr", "de"))
+		require.NoError(t, err)
+		assert.Nil(t, res.Translation)
+	})
+
+}
+
+func TestService_TranslateResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tr := mockcat.NewMockTranslator(ctrl)
+	s, err := cat.NewService(mockcat.NewMockImageGetter(ctrl), mockcat.NewMockFactGetter(ctrl), cat.WithTranslator(tr))
+	require.NoError(t, err)
+
+	tr.EXPECT().Translate(gomock.Any(), cat.Fact("Cats purr."), "de").Return(cat.Fact("Katzen schnurren."), nil)
+
+	stored := cat.CatResult{ImageURL: "some-image", Fact: "Cats purr.", Translation: &cat.Translation{Lang: "fr", Fact: "Les chats ronronnent."}}
+	res := s.TranslateResult(cat.WithLanguages(context.Background(), "de"), stored)
+	assert.Equal(t, &cat.Translation{Lang: "de", Fact: "Katzen schnurren."}, res.Translation)
+
+	res = s.TranslateResult(context.Background(), stored)
+	assert.Nil(t, res.Translation)
 }
@@ -0,0 +1,23 @@
+package cat
+
+import "net/http"
+
+// DoerFunc lets an ordinary function be used as a Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// DoerMiddleware wraps a Doer with some extra behaviour, such as retries or
+// metrics.
+type DoerMiddleware func(next Doer) Doer
+
+// Chain wraps d in middlewares. The first middleware is the outermost, so it
+// sees each request first and each response last.
+func Chain(d Doer, middlewares ...DoerMiddleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		d = middlewares[i](d)
+	}
+	return d
+}
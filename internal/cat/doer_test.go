@@ -0,0 +1,35 @@
+package cat_test
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	named := func(name string) cat.DoerMiddleware {
+		return func(next cat.Doer) cat.Doer {
+			return cat.DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				res, err := next.Do(req)
+				calls = append(calls, name+" after")
+				return res, err
+			})
+		}
+	}
+	d := cat.Chain(cat.DoerFunc(func(*http.Request) (*http.Response, error) {
+		calls = append(calls, "doer")
+		return &http.Response{StatusCode: http.StatusTeapot}, nil
+	}), named("outer"), named("inner"))
+
+	req, err := http.NewRequest(http.MethodGet, "http://some-url", nil)
+	require.NoError(t, err)
+	res, err := d.Do(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTeapot, res.StatusCode)
+	assert.Equal(t, []string{"outer before", "inner before", "doer", "inner after", "outer after"}, calls)
+}
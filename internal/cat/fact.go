@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/url"
+	"time"
 )
 
 type Fact string
@@ -14,20 +14,49 @@ type FactGetter interface {
 	GetFact(ctx context.Context) (Fact, error)
 }
 
+// SourcedFactGetter is a FactGetter that can say which provider each fact
+// came from, such as one routing between several.
+type SourcedFactGetter interface {
+	FactGetter
+	GetSourcedFact(ctx context.Context) (f Fact, provider string, err error)
+}
+
+// GetSourcedFact gets a fact from g, along with its provider if g is a
+// SourcedFactGetter, or "" if not.
+func GetSourcedFact(ctx context.Context, g FactGetter) (Fact, string, error) {
+	if sg, ok := g.(SourcedFactGetter); ok {
+		return sg.GetSourcedFact(ctx)
+	}
+	f, err := g.GetFact(ctx)
+	return f, "", err
+}
+
 type Doer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
 type FactService struct {
-	hc      Doer
-	baseUrl string
+	hc       Doer
+	baseUrl  string
+	provider FactProvider
 }
 
+// FactResponse is the response from DefaultFactProvider.
 type FactResponse struct {
 	Text string `json:"text"`
 }
 
-func NewFactService(hc Doer, baseUrl string) (*FactService, error) {
+type FactServiceOption func(f *FactService)
+
+// WithFactProvider makes the FactService call an API shaped like p, rather
+// than DefaultFactProvider.
+func WithFactProvider(p FactProvider) FactServiceOption {
+	return func(f *FactService) {
+		f.provider = p
+	}
+}
+
+func NewFactService(hc Doer, baseUrl string, opts ...FactServiceOption) (*FactService, error) {
 
 	if hc == nil {
 		return nil, ErrNilParam{Parameter: "hc"}
@@ -37,33 +66,49 @@ func NewFactService(hc Doer, baseUrl string) (*FactService, error) {
 		return nil, ErrNilParam{Parameter: "baseUrl"}
 	}
 
-	return &FactService{
-		hc:      hc,
-		baseUrl: baseUrl,
-	}, nil
+	f := &FactService{
+		hc:       hc,
+		baseUrl:  baseUrl,
+		provider: DefaultFactProvider,
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	if err := f.provider.validate(); err != nil {
+		return nil, err
+	}
+	return f, nil
 }
 
 func (f *FactService) GetFact(ctx context.Context) (Fact, error) {
-	u, err := url.Parse(f.baseUrl)
+	u, err := f.provider.URL(f.baseUrl)
 	if err != nil {
 		return "", err
 	}
-	u.Path = "/facts/random"
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return "", fmt.Errorf("creating request: %w", err)
 	}
+	start := time.Now()
 	resp, err := f.hc.Do(req)
 	if err != nil {
+		logUpstreamFailure(ctx, "fact", start, nil, err)
 		return "", fmt.Errorf("calling fact service: %w", err)
 	}
+	defer resp.Body.Close()
 
-	var fr FactResponse
-	err = json.NewDecoder(resp.Body).Decode(&fr)
+	var body interface{}
+	err = json.NewDecoder(resp.Body).Decode(&body)
 	if err != nil {
+		logUpstreamFailure(ctx, "fact", start, resp, err)
 		return "", fmt.Errorf("unmarshall Response: %w", err)
 	}
+	text, err := f.provider.Fact.GetString(body)
+	if err != nil {
+		logUpstreamFailure(ctx, "fact", start, resp, err)
+		return "", fmt.Errorf("%s response: %w", f.provider.Name, err)
+	}
 
-	return Fact(fr.Text), nil
+	return Fact(text), nil
 }
@@ -3,13 +3,16 @@ package cat_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
 	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"testing"
@@ -117,4 +120,30 @@ func TestFactService_GetFact(t *testing.T) {
 		assert.Error(t, err)
 
 	})
+
+	t.Run("Logs the upstream failure with the request-scoped logger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var buf bytes.Buffer
+		l := slog.New(slog.NewJSONHandler(&buf, nil)).With("request_id", "some-id")
+		ctx := logging.WithLogger(context.Background(), l)
+
+		d := mockcat.NewMockDoer(ctrl)
+		d.EXPECT().Do(gomock.Any()).Return(nil, errors.New("some-error"))
+
+		s, err := cat.NewFactService(d, "http://someURL")
+		require.NoError(t, err)
+
+		_, err = s.GetFact(ctx)
+		require.Error(t, err)
+
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "ERROR", line["level"])
+		assert.Equal(t, "some-id", line["request_id"])
+		assert.Equal(t, "fact", line["upstream"])
+		assert.Equal(t, "some-error", line["error"])
+		assert.Contains(t, line, "latency")
+	})
 }
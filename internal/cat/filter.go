@@ -0,0 +1,132 @@
+package cat
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Sizes and MIMETypes are the values the image upstream accepts for
+// ImageFilter.Size and ImageFilter.MIMETypes.
+var (
+	Sizes     = []string{"thumb", "small", "med", "full"}
+	MIMETypes = []string{"jpg", "png", "gif"}
+)
+
+// ImageFilter narrows down which images the upstream picks from. The zero
+// value matches any image.
+type ImageFilter struct {
+	Breed     string
+	Category  int
+	MIMETypes []string
+	Size      string
+}
+
+// ErrInvalidFilter is returned when an ImageFilter field has a value the
+// upstream doesn't accept.
+type ErrInvalidFilter struct {
+	Param string
+	Value string
+	// Allowed lists the accepted values, when there is a fixed set.
+	Allowed []string
+}
+
+func (e ErrInvalidFilter) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("invalid %s %q", e.Param, e.Value)
+	}
+	return fmt.Sprintf("invalid %s %q, must be one of %s", e.Param, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// ErrUnknownBreed is returned when an ImageFilter names a breed the upstream
+// doesn't know.
+type ErrUnknownBreed struct {
+	Breed string
+}
+
+func (e ErrUnknownBreed) Error() string {
+	return fmt.Sprintf("unknown breed %q", e.Breed)
+}
+
+// Validate checks the fields that have a fixed set of values. Breeds are
+// checked against the upstream by ValidateBreed.
+func (f ImageFilter) Validate() error {
+	for _, m := range f.MIMETypes {
+		if !contains(MIMETypes, m) {
+			return ErrInvalidFilter{Param: "mime_types", Value: m, Allowed: MIMETypes}
+		}
+	}
+	if f.Size != "" && !contains(Sizes, f.Size) {
+		return ErrInvalidFilter{Param: "size", Value: f.Size, Allowed: Sizes}
+	}
+	if f.Category < 0 {
+		return ErrInvalidFilter{Param: "category", Value: strconv.Itoa(f.Category)}
+	}
+	return nil
+}
+
+// ValidateBreed checks f.Breed is one of the breeds l lists.
+func (f ImageFilter) ValidateBreed(ctx context.Context, l BreedLister) error {
+	if f.Breed == "" {
+		return nil
+	}
+	breeds, err := l.ListBreeds(ctx)
+	if err != nil {
+		return err
+	}
+	for _, b := range breeds {
+		if b.ID == f.Breed {
+			return nil
+		}
+	}
+	return ErrUnknownBreed{Breed: f.Breed}
+}
+
+func (f ImageFilter) isZero() bool {
+	return f.Breed == "" && f.Category == 0 && len(f.MIMETypes) == 0 && f.Size == ""
+}
+
+// query adds f to q using the upstream's parameter names.
+func (f ImageFilter) query(q url.Values) {
+	if f.Breed != "" {
+		q.Set("breed_ids", f.Breed)
+	}
+	if f.Category != 0 {
+		q.Set("category_ids", strconv.Itoa(f.Category))
+	}
+	if len(f.MIMETypes) > 0 {
+		q.Set("mime_types", strings.Join(f.MIMETypes, ","))
+	}
+	if f.Size != "" {
+		q.Set("size", f.Size)
+	}
+}
+
+type imageFilterKey struct{}
+
+// WithImageFilter returns a copy of ctx asking ImageGetters for images
+// matching f.
+func WithImageFilter(ctx context.Context, f ImageFilter) context.Context {
+	if f.isZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, imageFilterKey{}, f)
+}
+
+// ImageFilterFromContext returns the filter stored by WithImageFilter, or the
+// zero ImageFilter.
+func ImageFilterFromContext(ctx context.Context) ImageFilter {
+	f, _ := ctx.Value(imageFilterKey{}).(ImageFilter)
+	return f
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
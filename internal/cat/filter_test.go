@@ -0,0 +1,82 @@
+package cat_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type breeds []cat.Breed
+
+func (b breeds) ListBreeds(context.Context) ([]cat.Breed, error) {
+	return b, nil
+}
+
+func TestImageFilter_Validate(t *testing.T) {
+	assert.NoError(t, cat.ImageFilter{}.Validate())
+	assert.NoError(t, cat.ImageFilter{MIMETypes: []string{"gif", "png"}, Size: "small", Category: 5}.Validate())
+
+	var e cat.ErrInvalidFilter
+	err := cat.ImageFilter{MIMETypes: []string{"gif", "bmp"}}.Validate()
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "mime_types", e.Param)
+	assert.Equal(t, "bmp", e.Value)
+
+	err = cat.ImageFilter{Size: "huge"}.Validate()
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "size", e.Param)
+	assert.Equal(t, cat.Sizes, e.Allowed)
+}
+
+func TestImageFilter_ValidateBreed(t *testing.T) {
+	ctx := context.Background()
+	l := breeds{{ID: "beng", Name: "Bengal"}}
+
+	assert.NoError(t, cat.ImageFilter{}.ValidateBreed(ctx, l))
+	assert.NoError(t, cat.ImageFilter{Breed: "beng"}.ValidateBreed(ctx, l))
+
+	var e cat.ErrUnknownBreed
+	require.True(t, errors.As(cat.ImageFilter{Breed: "dog"}.ValidateBreed(ctx, l), &e))
+	assert.Equal(t, "dog", e.Breed)
+}
+
+func TestImageService_GetImage_Filter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var req *http.Request
+	d := mockcat.NewMockDoer(ctrl)
+	d.EXPECT().Do(gomock.Any()).DoAndReturn(func(r *http.Request) (*http.Response, error) {
+		req = r
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`[{"url": "https://example.com/cat.gif"}]`)),
+		}, nil
+	})
+	s, err := cat.NewImageService(d, "https://api.example/v1/images/search?limit=1")
+	require.NoError(t, err)
+
+	ctx := cat.WithImageFilter(context.Background(), cat.ImageFilter{
+		Breed:     "beng",
+		Category:  1,
+		MIMETypes: []string{"gif", "png"},
+		Size:      "small",
+	})
+	_, err = s.GetImage(ctx)
+	require.NoError(t, err)
+
+	q := req.URL.Query()
+	assert.Equal(t, "1", q.Get("limit"))
+	assert.Equal(t, "beng", q.Get("breed_ids"))
+	assert.Equal(t, "1", q.Get("category_ids"))
+	assert.Equal(t, "gif,png", q.Get("mime_types"))
+	assert.Equal(t, "small", q.Get("size"))
+}
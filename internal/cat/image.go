@@ -2,27 +2,97 @@ package cat
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"mime"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
 )
 
+// ImageResponse is the response from DefaultImageProvider.
 type ImageResponse []struct {
-	URL string `json:"url"`
+	ID     string  `json:"id"`
+	URL    string  `json:"url"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Breeds []Breed `json:"breeds"`
 }
 
 type ImageURL string
 
+// Breed is a cat breed pictured in an Image.
+type Breed struct {
+	ID          string
+	Name        string
+	Origin      string `json:",omitempty"`
+	Temperament string `json:",omitempty"`
+}
+
+// Image is a cat picture and what the upstream knows about it. Everything but
+// URL is optional.
+type Image struct {
+	ID       string `json:",omitempty"`
+	URL      ImageURL
+	Width    int     `json:",omitempty"`
+	Height   int     `json:",omitempty"`
+	MIMEType string  `json:",omitempty"`
+	Breeds   []Breed `json:",omitempty"`
+}
+
 type ImageGetter interface {
-	GetImage(ctx context.Context) (ImageURL, error)
+	GetImage(ctx context.Context) (Image, error)
+}
+
+// ImageURLGetter is for callers that only want a picture's URL.
+type ImageURLGetter interface {
+	GetImageURL(ctx context.Context) (ImageURL, error)
+}
+
+type imageURLGetter struct {
+	g ImageGetter
+}
+
+// URLOnly adapts g for callers that only want URLs.
+func URLOnly(g ImageGetter) ImageURLGetter {
+	return imageURLGetter{g: g}
+}
+
+func (u imageURLGetter) GetImageURL(ctx context.Context) (ImageURL, error) {
+	i, err := u.g.GetImage(ctx)
+	return i.URL, err
 }
 
 type ImageService struct {
-	url string
-	hc  Doer
+	url      string
+	hc       Doer
+	provider ImageProvider
+
+	cache    *ImageCache
+	cacheURL string
+}
+
+type ImageServiceOption func(s *ImageService)
+
+// WithImageProvider makes the ImageService call an API shaped like p, rather
+// than DefaultImageProvider.
+func WithImageProvider(p ImageProvider) ImageServiceOption {
+	return func(s *ImageService) {
+		s.provider = p
+	}
+}
+
+// WithImageCache keeps images from an ImageKindBinary provider in c. Their
+// URL is urlPrefix followed by the image's ID in c, e.g.
+// "https://cats.example/images/".
+func WithImageCache(c *ImageCache, urlPrefix string) ImageServiceOption {
+	return func(s *ImageService) {
+		s.cache = c
+		s.cacheURL = urlPrefix
+	}
 }
 
-func NewImageService(hc Doer, url string) (*ImageService, error) {
+func NewImageService(hc Doer, url string, opts ...ImageServiceOption) (*ImageService, error) {
 	if hc == nil {
 		return nil, ErrNilParam{Parameter: "Doer"}
 	}
@@ -31,31 +101,84 @@ func NewImageService(hc Doer, url string) (*ImageService, error) {
 		return nil, ErrNilParam{Parameter: "url"}
 	}
 
-	return &ImageService{
-		url: url,
-		hc:  hc,
-	}, nil
+	s := &ImageService{
+		url:      url,
+		hc:       hc,
+		provider: DefaultImageProvider,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if err := s.provider.validate(); err != nil {
+		return nil, err
+	}
+	if s.provider.Kind == ImageKindBinary && s.cache == nil {
+		return nil, ErrNilParam{Parameter: "ImageCache"}
+	}
+	return s, nil
+}
+
+func (s *ImageService) GetImageURL(ctx context.Context) (ImageURL, error) {
+	return URLOnly(s).GetImageURL(ctx)
 }
 
-func (s *ImageService) GetImage(ctx context.Context) (ImageURL, error) {
+func (s *ImageService) GetImage(ctx context.Context) (Image, error) {
+
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return Image{}, err
+	}
+	if f := ImageFilterFromContext(ctx); !f.isZero() {
+		q := u.Query()
+		f.query(q)
+		u.RawQuery = q.Encode()
+	}
 
-	r, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return "", err
+		return Image{}, err
 	}
 
+	start := time.Now()
 	res, err := s.hc.Do(r)
 	if err != nil {
-		return "", err
+		logUpstreamFailure(ctx, "image", start, nil, err)
+		return Image{}, err
 	}
-	var x ImageResponse
-	err = json.NewDecoder(res.Body).Decode(&x)
+	defer res.Body.Close()
+
+	i, err := s.decode(res)
 	if err != nil {
-		return "", err
+		logUpstreamFailure(ctx, "image", start, res, err)
+		return Image{}, err
+	}
+	if i.MIMEType == "" {
+		i.MIMEType = mimeType(string(i.URL))
+	}
+	return i, nil
+}
+
+func (s *ImageService) decode(res *http.Response) (Image, error) {
+	if s.provider.Kind != ImageKindBinary {
+		return s.provider.decode(res)
 	}
-	if len(x) == 0 {
-		return "", errors.New("not long enough mate")
+	i, b, err := readBinary(res)
+	if err != nil {
+		return Image{}, err
 	}
+	i.URL = ImageURL(s.cacheURL + s.cache.Put(b, i.MIMEType))
+	return i, nil
+}
 
-	return ImageURL(x[0].URL), nil
+// mimeType guesses an image's type from its URL, as the upstream doesn't say.
+func mimeType(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	t := mime.TypeByExtension(strings.ToLower(path.Ext(u.Path)))
+	if t, _, err := mime.ParseMediaType(t); err == nil {
+		return t
+	}
+	return ""
 }
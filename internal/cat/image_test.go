@@ -355,6 +355,48 @@ func TestImageService_GetImage(t *testing.T) {
 		i, err := s.GetImage(ctx)
 
 		assert.NoError(t, err)
-		assert.Equal(t, cat.ImageURL("https://cdn2.thecatapi.com/images/y61B6bFCh.jpg"), i)
+		assert.Equal(t, cat.Image{
+			ID:       "y61B6bFCh",
+			URL:      "https://cdn2.thecatapi.com/images/y61B6bFCh.jpg",
+			Width:    898,
+			Height:   900,
+			MIMEType: "image/jpeg",
+			Breeds: []cat.Breed{{
+				ID:          "esho",
+				Name:        "Exotic Shorthair",
+				Origin:      "United States",
+				Temperament: "Affectionate, Sweet, Loyal, Quiet, Peaceful",
+			}},
+		}, i)
 	})
+
+	t.Run("GetImageURL returns just the URL", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		d := mockcat.NewMockDoer(ctrl)
+		s, err := cat.NewImageService(d, "someurl")
+		require.NoError(t, err)
+
+		d.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			Body: ioutil.NopCloser(bytes.NewBufferString(`[{"id": "abc", "url": "https://example.com/cat.png"}]`)),
+		}, nil)
+		u, err := s.GetImageURL(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, cat.ImageURL("https://example.com/cat.png"), u)
+	})
+}
+
+func TestURLOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := mockcat.NewMockImageGetter(ctrl)
+	g.EXPECT().GetImage(gomock.Any()).Return(cat.Image{ID: "abc", URL: "some-url", Width: 10}, nil)
+
+	u, err := cat.URLOnly(g).GetImageURL(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, cat.ImageURL("some-url"), u)
 }
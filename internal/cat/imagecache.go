@@ -0,0 +1,79 @@
+package cat
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// ImageCache keeps the bytes of images that upstreams return inline, so
+// results can point at the server's own URL for them rather than carry the
+// image. It holds up to maxBytes of images, dropping the least recently
+// used first.
+type ImageCache struct {
+	maxBytes int64
+
+	mu    sync.Mutex
+	size  int64
+	items map[string]*list.Element
+	// lru holds *cachedImage, most recently used at the front.
+	lru *list.List
+}
+
+type cachedImage struct {
+	id       string
+	data     []byte
+	mimeType string
+}
+
+func NewImageCache(maxBytes int64) (*ImageCache, error) {
+	if maxBytes < MaxBinaryImageSize {
+		return nil, errors.New("image cache must hold at least one image of MaxBinaryImageSize")
+	}
+	return &ImageCache{
+		maxBytes: maxBytes,
+		items:    make(map[string]*list.Element),
+		lru:      list.New(),
+	}, nil
+}
+
+// Put stores data and returns its ID, which is derived from the bytes so the
+// same image is only kept once.
+func (c *ImageCache) Put(data []byte, mimeType string) string {
+	h := sha256.Sum256(data)
+	id := hex.EncodeToString(h[:16])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[id]; ok {
+		c.lru.MoveToFront(e)
+		return id
+	}
+	for c.size+int64(len(data)) > c.maxBytes {
+		e := c.lru.Back()
+		ci := e.Value.(*cachedImage)
+		c.lru.Remove(e)
+		delete(c.items, ci.id)
+		c.size -= int64(len(ci.data))
+	}
+	c.items[id] = c.lru.PushFront(&cachedImage{id: id, data: data, mimeType: mimeType})
+	c.size += int64(len(data))
+	return id
+}
+
+// Get returns the image stored under id, if it hasn't been dropped.
+func (c *ImageCache) Get(id string) (data []byte, mimeType string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[id]
+	if !ok {
+		return nil, "", false
+	}
+	c.lru.MoveToFront(e)
+	ci := e.Value.(*cachedImage)
+	return ci.data, ci.mimeType, true
+}
@@ -0,0 +1,255 @@
+package cat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/jsonpath"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Kinds of image API an ImageProvider can describe.
+const (
+	// ImageKindArray APIs return a JSON array of image objects.
+	ImageKindArray = "array"
+	// ImageKindObject APIs return a single JSON image object.
+	ImageKindObject = "object"
+	// ImageKindRedirect APIs redirect to the image; its URL is the final
+	// Location.
+	ImageKindRedirect = "redirect"
+	// ImageKindBinary APIs respond with the image itself, which is kept in
+	// an ImageCache and served from the server's own URL.
+	ImageKindBinary = "binary"
+)
+
+// MaxBinaryImageSize is the largest image an ImageKindBinary provider may
+// return.
+const MaxBinaryImageSize = 5 << 20
+
+// ErrNoImage is returned when an upstream response has no image in it.
+var ErrNoImage = errors.New("no image in response")
+
+// ImageFields says where in a JSON image object each Image field is. Only URL
+// is required; the others are skipped when empty or missing.
+type ImageFields struct {
+	ID     jsonpath.Path `json:"id"`
+	URL    jsonpath.Path `json:"url"`
+	Width  jsonpath.Path `json:"width"`
+	Height jsonpath.Path `json:"height"`
+	// Breeds points at an array of objects shaped like Breed.
+	Breeds jsonpath.Path `json:"breeds"`
+}
+
+// DefaultImageFields fit thecatapi.com.
+var DefaultImageFields = ImageFields{
+	ID:     jsonpath.MustCompile("$.id"),
+	URL:    jsonpath.MustCompile("$.url"),
+	Width:  jsonpath.MustCompile("$.width"),
+	Height: jsonpath.MustCompile("$.height"),
+	Breeds: jsonpath.MustCompile("$.breeds"),
+}
+
+// ImageProvider describes an image API: what kind of response it gives and,
+// for JSON kinds, where the fields are.
+type ImageProvider struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Fields defaults to DefaultImageFields for JSON kinds.
+	Fields *ImageFields `json:"fields,omitempty"`
+}
+
+// DefaultImageProvider is thecatapi.com.
+var DefaultImageProvider = ImageProvider{Name: "thecatapi", Kind: ImageKindArray}
+
+var builtinImageProviders = []ImageProvider{
+	DefaultImageProvider,
+	{Name: "random.cat", Kind: ImageKindObject, Fields: &ImageFields{URL: jsonpath.MustCompile("$.file")}},
+	{Name: "cataas", Kind: ImageKindBinary},
+	{Name: "redirect", Kind: ImageKindRedirect},
+}
+
+func (p ImageProvider) validate() error {
+	if p.Name == "" {
+		return errors.New("image provider needs a name")
+	}
+	switch p.Kind {
+	case ImageKindArray, ImageKindObject:
+		if p.Fields != nil && p.Fields.URL.String() == "" {
+			return fmt.Errorf("image provider %s needs a url field", p.Name)
+		}
+	case ImageKindRedirect, ImageKindBinary:
+	default:
+		return fmt.Errorf("image provider %s has unknown kind %q", p.Name, p.Kind)
+	}
+	return nil
+}
+
+// decode reads the Image out of res. ImageKindBinary responses are read by
+// readBinary instead.
+func (p ImageProvider) decode(res *http.Response) (Image, error) {
+	if p.Kind == ImageKindRedirect {
+		return decodeRedirect(res)
+	}
+
+	fields := DefaultImageFields
+	if p.Fields != nil {
+		fields = *p.Fields
+	}
+	var body interface{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return Image{}, err
+	}
+	if p.Kind == ImageKindArray {
+		arr, ok := body.([]interface{})
+		if !ok || len(arr) == 0 {
+			return Image{}, ErrNoImage
+		}
+		body = arr[0]
+	}
+	return fields.image(body)
+}
+
+func (f ImageFields) image(obj interface{}) (Image, error) {
+	u, err := f.URL.GetString(obj)
+	if err != nil {
+		return Image{}, err
+	}
+	if u == "" {
+		return Image{}, ErrNoImage
+	}
+
+	i := Image{URL: ImageURL(u)}
+	if f.ID.String() != "" {
+		i.ID, _ = f.ID.GetString(obj)
+	}
+	if f.Width.String() != "" {
+		if w, err := f.Width.Get(obj); err == nil {
+			n, _ := w.(float64)
+			i.Width = int(n)
+		}
+	}
+	if f.Height.String() != "" {
+		if h, err := f.Height.Get(obj); err == nil {
+			n, _ := h.(float64)
+			i.Height = int(n)
+		}
+	}
+	if f.Breeds.String() != "" {
+		if b, err := f.Breeds.Get(obj); err == nil {
+			// Round trip through JSON to reuse Breed's decoding.
+			if raw, err := json.Marshal(b); err == nil {
+				_ = json.Unmarshal(raw, &i.Breeds)
+			}
+		}
+	}
+	return i, nil
+}
+
+// decodeRedirect takes the image URL from a redirect response's Location.
+// The Doer should hand redirects back rather than follow them, so the image
+// is never downloaded; if it did follow them, the final request's URL is
+// used. The body is never read.
+func decodeRedirect(res *http.Response) (Image, error) {
+	if loc, err := res.Location(); err == nil {
+		return Image{URL: ImageURL(loc.String())}, nil
+	}
+	if res.Request == nil || res.StatusCode != http.StatusOK {
+		return Image{}, ErrNoImage
+	}
+	return Image{URL: ImageURL(res.Request.URL.String())}, nil
+}
+
+// readBinary reads an image response, returning the image's bytes and an
+// Image without a URL. Its type is sniffed from the bytes, never taken from
+// the upstream's Content-Type, as the bytes are served from our origin.
+func readBinary(res *http.Response) (Image, []byte, error) {
+	if res.StatusCode != http.StatusOK {
+		return Image{}, nil, fmt.Errorf("image service returned %d", res.StatusCode)
+	}
+	b, err := io.ReadAll(io.LimitReader(res.Body, MaxBinaryImageSize+1))
+	if err != nil {
+		return Image{}, nil, err
+	}
+	if len(b) > MaxBinaryImageSize {
+		return Image{}, nil, fmt.Errorf("image larger than %d bytes", MaxBinaryImageSize)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return Image{}, nil, fmt.Errorf("decoding image: %w", err)
+	}
+	return Image{Width: cfg.Width, Height: cfg.Height, MIMEType: "image/" + format}, b, nil
+}
+
+// ImageProviders is a registry of ImageProviders by name. It starts with the
+// built-in providers; more can be registered or loaded from config.
+type ImageProviders struct {
+	mu        sync.RWMutex
+	providers map[string]ImageProvider
+}
+
+func NewImageProviders() *ImageProviders {
+	r := &ImageProviders{providers: make(map[string]ImageProvider)}
+	for _, p := range builtinImageProviders {
+		r.providers[p.Name] = p
+	}
+	return r
+}
+
+// Register adds p, replacing any provider with the same name.
+func (r *ImageProviders) Register(p ImageProvider) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Name] = p
+	return nil
+}
+
+// Load registers every provider in the JSON array at path, e.g.
+// [{"name": "example", "kind": "object", "fields": {"url": "$.image.src"}}].
+func (r *ImageProviders) Load(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading image providers: %w", err)
+	}
+	var providers []ImageProvider
+	if err := json.Unmarshal(b, &providers); err != nil {
+		return fmt.Errorf("parsing image providers: %w", err)
+	}
+	for _, p := range providers {
+		if err := r.Register(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ImageProviders) Get(name string) (ImageProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names returns every registered provider's name, sorted.
+func (r *ImageProviders) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for n := range r.providers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
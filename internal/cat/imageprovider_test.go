@@ -0,0 +1,186 @@
+package cat_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/jsonpath"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImageProviders(t *testing.T) {
+	r := cat.NewImageProviders()
+	assert.Equal(t, []string{"cataas", "random.cat", "redirect", "thecatapi"}, r.Names())
+
+	assert.Error(t, r.Register(cat.ImageProvider{Name: "bad", Kind: "video"}))
+	assert.Error(t, r.Register(cat.ImageProvider{Name: "bad", Kind: cat.ImageKindObject, Fields: &cat.ImageFields{}}))
+
+	path := filepath.Join(t.TempDir(), "providers.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "example", "kind": "object", "fields": {"url": "$.image.src", "width": "$.image.w"}}
+	]`), 0600))
+	require.NoError(t, r.Load(path))
+
+	p, ok := r.Get("example")
+	require.True(t, ok)
+	assert.Equal(t, "$.image.w", p.Fields.Width.String())
+}
+
+func getImage(t *testing.T, p cat.ImageProvider, res *http.Response, opts ...cat.ImageServiceOption) (cat.Image, error) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d := mockcat.NewMockDoer(ctrl)
+	d.EXPECT().Do(gomock.Any()).Return(res, nil)
+	s, err := cat.NewImageService(d, "https://images.example/cat", append(opts, cat.WithImageProvider(p))...)
+	require.NoError(t, err)
+	return s.GetImage(context.Background())
+}
+
+func TestImageService_GetImage_Providers(t *testing.T) {
+	t.Run("Object", func(t *testing.T) {
+		p := cat.ImageProvider{Name: "example", Kind: cat.ImageKindObject, Fields: &cat.ImageFields{
+			URL:   jsonpath.MustCompile("$.image.src"),
+			Width: jsonpath.MustCompile("$.image.w"),
+		}}
+		i, err := getImage(t, p, &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"image": {"src": "https://cdn.example/a.gif", "w": 320}}`)),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, cat.Image{URL: "https://cdn.example/a.gif", Width: 320, MIMEType: "image/gif"}, i)
+	})
+
+	t.Run("Object with the built-in random.cat mapping", func(t *testing.T) {
+		p, _ := cat.NewImageProviders().Get("random.cat")
+		i, err := getImage(t, p, &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"file": "https://purr.example/b.jpg"}`)),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, cat.ImageURL("https://purr.example/b.jpg"), i.URL)
+	})
+
+	t.Run("Redirect with the Location header", func(t *testing.T) {
+		p, _ := cat.NewImageProviders().Get("redirect")
+		req := httptest.NewRequest(http.MethodGet, "https://images.example/cat", nil)
+		i, err := getImage(t, p, &http.Response{
+			StatusCode: http.StatusFound,
+			Header:     http.Header{"Location": []string{"/images/c.png"}},
+			Request:    req,
+			Body:       io.NopCloser(strings.NewReader("")),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, cat.Image{URL: "https://images.example/images/c.png", MIMEType: "image/png"}, i)
+	})
+
+	t.Run("Redirect already followed by the Doer", func(t *testing.T) {
+		p, _ := cat.NewImageProviders().Get("redirect")
+		i, err := getImage(t, p, &http.Response{
+			StatusCode: http.StatusOK,
+			Request:    httptest.NewRequest(http.MethodGet, "https://cdn.example/d.jpg", nil),
+			Body:       io.NopCloser(strings.NewReader("image bytes we never read")),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, cat.ImageURL("https://cdn.example/d.jpg"), i.URL)
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 3, 2))))
+
+		cache, err := cat.NewImageCache(cat.MaxBinaryImageSize)
+		require.NoError(t, err)
+		p, _ := cat.NewImageProviders().Get("cataas")
+		i, err := getImage(t, p, &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"image/png"}},
+			Body:       io.NopCloser(bytes.NewReader(buf.Bytes())),
+		}, cat.WithImageCache(cache, "https://cats.example/images/"))
+
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(i.URL), "https://cats.example/images/"))
+		assert.Equal(t, 3, i.Width)
+		assert.Equal(t, 2, i.Height)
+		assert.Equal(t, "image/png", i.MIMEType)
+
+		b, mt, ok := cache.Get(strings.TrimPrefix(string(i.URL), "https://cats.example/images/"))
+		require.True(t, ok)
+		assert.Equal(t, buf.Bytes(), b)
+		assert.Equal(t, "image/png", mt)
+	})
+
+	t.Run("Binary typed from its bytes, not the upstream's Content-Type", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+
+		cache, err := cat.NewImageCache(cat.MaxBinaryImageSize)
+		require.NoError(t, err)
+		p, _ := cat.NewImageProviders().Get("cataas")
+		i, err := getImage(t, p, &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/html"}},
+			Body:       io.NopCloser(bytes.NewReader(buf.Bytes())),
+		}, cat.WithImageCache(cache, "/images/"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", i.MIMEType)
+		_, mt, ok := cache.Get(strings.TrimPrefix(string(i.URL), "/images/"))
+		require.True(t, ok)
+		assert.Equal(t, "image/png", mt)
+	})
+
+	t.Run("Binary that isn't an image", func(t *testing.T) {
+		cache, err := cat.NewImageCache(cat.MaxBinaryImageSize)
+		require.NoError(t, err)
+		p, _ := cat.NewImageProviders().Get("cataas")
+		_, err = getImage(t, p, &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("<html>oops</html>")),
+		}, cat.WithImageCache(cache, "/images/"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Binary without an ImageCache", func(t *testing.T) {
+		p, _ := cat.NewImageProviders().Get("cataas")
+		_, err := cat.NewImageService(http.DefaultClient, "https://images.example/cat", cat.WithImageProvider(p))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestImageCache(t *testing.T) {
+	_, err := cat.NewImageCache(1)
+	assert.Error(t, err)
+
+	c, err := cat.NewImageCache(cat.MaxBinaryImageSize)
+	require.NoError(t, err)
+
+	big := make([]byte, cat.MaxBinaryImageSize/2)
+	a := c.Put(append([]byte("a"), big...), "image/png")
+	assert.Equal(t, a, c.Put(append([]byte("a"), big...), "image/png"), "the same bytes get the same ID")
+	b := c.Put(append([]byte("b"), big...), "image/png")
+
+	_, _, ok := c.Get(a)
+	assert.False(t, ok, "the oldest image is dropped to stay within maxBytes")
+	_, _, ok = c.Get(b)
+	assert.True(t, ok)
+}
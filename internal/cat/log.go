@@ -0,0 +1,23 @@
+package cat
+
+import (
+	"context"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// logUpstreamFailure logs a failed upstream call with the request-scoped
+// logger, so the request ID is attached automatically.
+func logUpstreamFailure(ctx context.Context, upstream string, start time.Time, res *http.Response, err error) {
+	attrs := []slog.Attr{
+		slog.String("upstream", upstream),
+		slog.Duration("latency", time.Since(start)),
+		slog.String("error", err.Error()),
+	}
+	if res != nil {
+		attrs = append(attrs, slog.Int("status", res.StatusCode))
+	}
+	logging.FromContext(ctx).LogAttrs(ctx, slog.LevelError, "upstream call failed", attrs...)
+}
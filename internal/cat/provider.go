@@ -0,0 +1,122 @@
+package cat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/jsonpath"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+)
+
+// FactProvider describes a fact API: where to ask it for a fact, and where
+// in its JSON response the fact is.
+type FactProvider struct {
+	Name string `json:"name"`
+	// Path replaces the path of the service's base URL.
+	Path  string            `json:"path"`
+	Query map[string]string `json:"query,omitempty"`
+	Fact  jsonpath.Path     `json:"fact"`
+}
+
+// DefaultFactProvider is cat-fact.herokuapp.com.
+var DefaultFactProvider = FactProvider{Name: "cat-fact", Path: "/facts/random", Fact: jsonpath.MustCompile("$.text")}
+
+var builtinFactProviders = []FactProvider{
+	DefaultFactProvider,
+	{Name: "catfact.ninja", Path: "/fact", Fact: jsonpath.MustCompile("$.fact")},
+	{Name: "meowfacts", Path: "/", Fact: jsonpath.MustCompile("$.data[0]")},
+}
+
+// URL returns the address to fetch a fact from, given the service's base URL.
+func (p FactProvider) URL(base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	u.Path = p.Path
+	if len(p.Query) > 0 {
+		q := u.Query()
+		for k, v := range p.Query {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
+}
+
+func (p FactProvider) validate() error {
+	if p.Name == "" {
+		return errors.New("fact provider needs a name")
+	}
+	if p.Fact.String() == "" {
+		return fmt.Errorf("fact provider %s needs a fact path", p.Name)
+	}
+	return nil
+}
+
+// FactProviders is a registry of FactProviders by name. It starts with the
+// built-in providers; more can be registered or loaded from config.
+type FactProviders struct {
+	mu        sync.RWMutex
+	providers map[string]FactProvider
+}
+
+func NewFactProviders() *FactProviders {
+	r := &FactProviders{providers: make(map[string]FactProvider)}
+	for _, p := range builtinFactProviders {
+		r.providers[p.Name] = p
+	}
+	return r
+}
+
+// Register adds p, replacing any provider with the same name.
+func (r *FactProviders) Register(p FactProvider) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Name] = p
+	return nil
+}
+
+// Load registers every provider in the JSON array at path, e.g.
+// [{"name": "example", "path": "/api/fact", "query": {"lang": "en"}, "fact": "$.data.fact"}].
+func (r *FactProviders) Load(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading fact providers: %w", err)
+	}
+	var providers []FactProvider
+	if err := json.Unmarshal(b, &providers); err != nil {
+		return fmt.Errorf("parsing fact providers: %w", err)
+	}
+	for _, p := range providers {
+		if err := r.Register(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *FactProviders) Get(name string) (FactProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names returns every registered provider's name, sorted.
+func (r *FactProviders) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for n := range r.providers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,95 @@
+package cat_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/jsonpath"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFactProvider_URL(t *testing.T) {
+	p := cat.FactProvider{Name: "example", Path: "/api/fact", Query: map[string]string{"max_length": "140"}}
+
+	u, err := p.URL("https://facts.example/ignored?key=1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://facts.example/api/fact?key=1&max_length=140", u)
+}
+
+func TestFactProviders(t *testing.T) {
+	r := cat.NewFactProviders()
+	assert.Equal(t, []string{"cat-fact", "catfact.ninja", "meowfacts"}, r.Names())
+
+	assert.Error(t, r.Register(cat.FactProvider{Name: "no-path"}))
+
+	path := filepath.Join(t.TempDir(), "providers.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "example", "path": "/api/fact", "query": {"lang": "en"}, "fact": "$.data.fact"}
+	]`), 0600))
+	require.NoError(t, r.Load(path))
+
+	p, ok := r.Get("example")
+	require.True(t, ok)
+	assert.Equal(t, "/api/fact", p.Path)
+	assert.Equal(t, "$.data.fact", p.Fact.String())
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name": "bad", "fact": "data.fact"}]`), 0600))
+	assert.Error(t, r.Load(path))
+}
+
+func TestFactService_GetFact_Providers(t *testing.T) {
+	r := cat.NewFactProviders()
+	require.NoError(t, r.Register(cat.FactProvider{Name: "nested", Path: "/v2/facts", Fact: jsonpath.MustCompile("$.results[0].body")}))
+
+	for name, tc := range map[string]struct {
+		body, wantURL string
+	}{
+		"catfact.ninja": {`{"fact": "some-fact", "length": 9}`, "https://api.example/fact"},
+		"meowfacts":     {`{"data": ["some-fact"]}`, "https://api.example/"},
+		"nested":        {`{"results": [{"body": "some-fact"}]}`, "https://api.example/v2/facts"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			p, ok := r.Get(name)
+			require.True(t, ok)
+			d := mockcat.NewMockDoer(ctrl)
+			d.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, tc.wantURL, req.URL.String())
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(tc.body))}, nil
+			})
+
+			s, err := cat.NewFactService(d, "https://api.example", cat.WithFactProvider(p))
+			require.NoError(t, err)
+			f, err := s.GetFact(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, cat.Fact("some-fact"), f)
+		})
+	}
+
+	t.Run("Returns an error when the fact isn't where the provider says", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		d := mockcat.NewMockDoer(ctrl)
+		d.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"text": "wrong shape"}`))}, nil)
+
+		p, _ := r.Get("catfact.ninja")
+		s, err := cat.NewFactService(d, "https://api.example", cat.WithFactProvider(p))
+		require.NoError(t, err)
+		_, err = s.GetFact(context.Background())
+
+		assert.EqualError(t, err, `catfact.ninja response: jsonpath $.fact: no field "fact"`)
+	})
+}
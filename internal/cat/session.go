@@ -0,0 +1,50 @@
+package cat
+
+import (
+	"context"
+)
+
+// DefaultMaxAttempts is how many times GetImageAndFact fetches before giving
+// up on finding something the session hasn't seen and serving a repeat.
+const DefaultMaxAttempts = 5
+
+// SessionHistory remembers which facts and images each session has been
+// shown. Implementations decide how long an entry counts as seen.
+type SessionHistory interface {
+	// Claim records key as seen by session and reports whether it was
+	// unseen before. Checking and recording happen together, so concurrent
+	// requests from one session can't both be given the same key.
+	Claim(session, key string) bool
+}
+
+type sessionKey struct{}
+
+// WithSession returns a copy of ctx for the given session.
+func WithSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFromContext returns the session stored by WithSession, or "".
+func SessionFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(sessionKey{}).(string)
+	return s
+}
+
+type ServiceOption func(s *Service)
+
+// WithSessionHistory makes GetImageAndFact avoid repeating a fact or image
+// to the session on the context, re-fetching up to maxAttempts times.
+func WithSessionHistory(h SessionHistory, maxAttempts int) ServiceOption {
+	return func(s *Service) {
+		s.history = h
+		s.maxAttempts = maxAttempts
+	}
+}
+
+func factKey(f Fact) string {
+	return "fact:" + string(f)
+}
+
+func imageKey(i ImageURL) string {
+	return "image:" + string(i)
+}
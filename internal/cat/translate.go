@@ -0,0 +1,103 @@
+package cat
+
+import (
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"golang.org/x/text/language"
+)
+
+// SourceLanguage is the language facts arrive in from upstream.
+const SourceLanguage = "en"
+
+// ErrUnsupportedLanguage is returned by a Translator that can't translate
+// into the language asked for at all.
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// ErrNoTranslation is returned by a Translator that supports the language
+// but has no translation for this particular fact.
+var ErrNoTranslation = errors.New("no translation")
+
+// Translator translates facts out of SourceLanguage. lang is a BCP 47
+// language tag such as "de" or "pt-BR".
+type Translator interface {
+	Translate(ctx context.Context, text Fact, lang string) (Fact, error)
+}
+
+// ResultTranslator fills in a result's Translation for the languages on the
+// context. Results that are shared or stored are picked without the
+// caller's languages and translated each time they are read.
+type ResultTranslator interface {
+	TranslateResult(ctx context.Context, c CatResult) CatResult
+}
+
+// Translation is a fact in another language, alongside the original in
+// CatResult.Fact.
+type Translation struct {
+	Lang string
+	Fact Fact
+}
+
+type languagesKey struct{}
+
+// WithLanguages returns a copy of ctx carrying the caller's preferred
+// languages, most preferred first.
+func WithLanguages(ctx context.Context, langs ...string) context.Context {
+	return context.WithValue(ctx, languagesKey{}, langs)
+}
+
+// LanguagesFromContext returns the languages stored by WithLanguages.
+func LanguagesFromContext(ctx context.Context) []string {
+	langs, _ := ctx.Value(languagesKey{}).([]string)
+	return langs
+}
+
+// WithTranslator makes GetImageAndFact translate the fact into the first of
+// the context's languages that t supports.
+func WithTranslator(t Translator) ServiceOption {
+	return func(s *Service) {
+		s.translator = t
+	}
+}
+
+// translate returns f in the most preferred language the translator
+// supports, or nil if the caller is happy with the original. Translation is
+// best effort: failures are logged and the original is served instead.
+func (s *Service) translate(ctx context.Context, f Fact) *Translation {
+	if s.translator == nil {
+		return nil
+	}
+	for _, lang := range LanguagesFromContext(ctx) {
+		if isSourceLanguage(lang) {
+			return nil
+		}
+		t, err := s.translator.Translate(ctx, f, lang)
+		if errors.Is(err, ErrUnsupportedLanguage) || errors.Is(err, ErrNoTranslation) {
+			continue
+		}
+		if err != nil {
+			logging.FromContext(ctx).Warn("translating fact", "lang", lang, "error", err)
+			return nil
+		}
+		return &Translation{Lang: lang, Fact: t}
+	}
+	return nil
+}
+
+// TranslateResult sets c.Translation for the context's languages, replacing
+// any translation c already had.
+func (s *Service) TranslateResult(ctx context.Context, c CatResult) CatResult {
+	c.Translation = s.translate(ctx, c.Fact)
+	return c
+}
+
+var sourceBase, _ = language.Make(SourceLanguage).Base()
+
+// isSourceLanguage reports whether lang is a variant of SourceLanguage, such
+// as "en-GB", that needs no translation.
+func isSourceLanguage(lang string) bool {
+	// Base guesses at a language for unknown tags, so only trust an exact
+	// match.
+	b, conf := language.Make(lang).Base()
+	return conf == language.Exact && b == sourceBase
+}
@@ -0,0 +1,264 @@
+package corpus
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Entry is a single distinct fact.
+type Entry struct {
+	Text      string    `json:"text"`
+	Provider  string    `json:"provider"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Count     int       `json:"count"`
+}
+
+// Normalize tidies fact text for storage: trimmed, with runs of whitespace
+// collapsed to a single space and typographic quotes made plain.
+func Normalize(text string) string {
+	text = strings.NewReplacer("‘", "'", "’", "'", "“", `"`, "”", `"`).Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// key is what two facts must share to count as duplicates: the normalized
+// text lowercased, without punctuation.
+func key(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return -1
+	}, Normalize(text))
+}
+
+// Corpus is a de-duplicated set of facts. With a path, every change is
+// appended to a JSON lines log that is replayed on start and rewritten once
+// it is mostly superseded records.
+type Corpus struct {
+	path string
+
+	mu      sync.RWMutex
+	entries map[string]*Entry
+	f       *os.File
+	records int
+	// written counts appends, so callers can wait for theirs to be synced.
+	written uint64
+
+	// syncMu lets concurrent Adds share one fsync, without holding mu while
+	// it runs. synced is the last append known to be on disk.
+	syncMu sync.Mutex
+	synced uint64
+}
+
+// NewCorpus loads the corpus at path. An empty path keeps it in memory.
+func NewCorpus(path string) (*Corpus, error) {
+	c := &Corpus{path: path, entries: make(map[string]*Entry)}
+	if path == "" {
+		return c, nil
+	}
+
+	if err := c.replay(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening corpus: %w", err)
+	}
+	c.f = f
+	return c, nil
+}
+
+func (c *Corpus) replay() error {
+	f, err := os.Open(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening corpus: %w", err)
+	}
+	defer f.Close()
+
+	var valid int64
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		e := new(Entry)
+		if err := json.Unmarshal(sc.Bytes(), e); err != nil {
+			// A torn write from a crash can only be the last line; drop
+			// it and everything after, so the next append starts on a
+			// line of its own.
+			break
+		}
+		c.entries[key(e.Text)] = e
+		c.records++
+		valid += int64(len(sc.Bytes())) + 1
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("reading corpus: %w", err)
+	}
+
+	if fi, err := f.Stat(); err == nil && fi.Size() > valid {
+		if err := os.Truncate(c.path, valid); err != nil {
+			return fmt.Errorf("truncating corpus: %w", err)
+		}
+	}
+	return nil
+}
+
+// Add records that provider returned text at time at. It reports whether the
+// fact was new.
+func (c *Corpus) Add(text, provider string, at time.Time) (bool, error) {
+	text = Normalize(text)
+	k := key(text)
+	if k == "" {
+		return false, nil
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[k]
+	if ok {
+		e.LastSeen = at
+		e.Count++
+	} else {
+		e = &Entry{Text: text, Provider: provider, FirstSeen: at, LastSeen: at, Count: 1}
+		c.entries[k] = e
+	}
+	seq, err := c.write(*e)
+	c.mu.Unlock()
+	if err != nil {
+		return !ok, err
+	}
+	return !ok, c.sync(seq)
+}
+
+// write appends e to the log, returning its sequence number for sync.
+// Callers hold mu.
+func (c *Corpus) write(e Entry) (uint64, error) {
+	if c.f == nil {
+		return 0, nil
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.f.Write(append(b, '\n')); err != nil {
+		return 0, fmt.Errorf("writing corpus: %w", err)
+	}
+	c.records++
+	c.written++
+	if c.records > 1000 && c.records > 2*len(c.entries) {
+		return c.written, c.rewrite()
+	}
+	return c.written, nil
+}
+
+// sync makes sure the log is on disk up to append seq. Concurrent callers
+// share one fsync, and mu isn't held while it runs.
+func (c *Corpus) sync(seq uint64) error {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	if seq == 0 || c.synced >= seq {
+		return nil
+	}
+
+	c.mu.RLock()
+	f, written := c.f, c.written
+	c.mu.RUnlock()
+	// A file closed since is one rewrite replaced with a synced copy.
+	if err := f.Sync(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return fmt.Errorf("syncing corpus: %w", err)
+	}
+	c.synced = written
+	return nil
+}
+
+// rewrite replaces the log with one record per entry. Callers hold mu.
+func (c *Corpus) rewrite() error {
+	tmp := c.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("creating corpus: %w", err)
+	}
+	if err := writeEntries(f, c.sorted()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing corpus: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		f.Close()
+		return fmt.Errorf("replacing corpus: %w", err)
+	}
+	_ = c.f.Close()
+	c.f = f
+	c.records = len(c.entries)
+	return nil
+}
+
+// Entries returns every fact, oldest first.
+func (c *Corpus) Entries() []Entry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sorted()
+}
+
+func (c *Corpus) sorted() []Entry {
+	out := make([]Entry, 0, len(c.entries))
+	for _, e := range c.entries {
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].FirstSeen.Equal(out[j].FirstSeen) {
+			return out[i].Text < out[j].Text
+		}
+		return out[i].FirstSeen.Before(out[j].FirstSeen)
+	})
+	return out
+}
+
+func (c *Corpus) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// Export writes every fact to w as JSON lines, oldest first. The facts are
+// copied first, so a slow w doesn't hold up Add.
+func (c *Corpus) Export(w io.Writer) error {
+	return writeEntries(w, c.Entries())
+}
+
+func writeEntries(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func (c *Corpus) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.f == nil {
+		return nil
+	}
+	return c.f.Close()
+}
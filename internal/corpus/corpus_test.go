@@ -0,0 +1,168 @@
+package corpus_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/corpus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, `Cats can't "fly".`, corpus.Normalize("  Cats  can’t\n“fly”. "))
+}
+
+func TestCorpus(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("De-duplicates ignoring case, spacing and punctuation", func(t *testing.T) {
+		c, err := corpus.NewCorpus("")
+		require.NoError(t, err)
+
+		added, err := c.Add("Cats sleep a lot.", "catfact", t0)
+		require.NoError(t, err)
+		assert.True(t, added)
+
+		added, err = c.Add("  cats SLEEP a lot ", "other", t0.Add(time.Hour))
+		require.NoError(t, err)
+		assert.False(t, added)
+
+		added, err = c.Add("   ", "catfact", t0)
+		require.NoError(t, err)
+		assert.False(t, added)
+
+		entries := c.Entries()
+		require.Len(t, entries, 1)
+		assert.Equal(t, corpus.Entry{
+			Text:      "Cats sleep a lot.",
+			Provider:  "catfact",
+			FirstSeen: t0,
+			LastSeen:  t0.Add(time.Hour),
+			Count:     2,
+		}, entries[0])
+	})
+
+	t.Run("Survives a restart", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "corpus.jsonl")
+		c, err := corpus.NewCorpus(path)
+		require.NoError(t, err)
+		_, err = c.Add("first", "catfact", t0)
+		require.NoError(t, err)
+		_, err = c.Add("second", "catfact", t0.Add(time.Minute))
+		require.NoError(t, err)
+		_, err = c.Add("first", "catfact", t0.Add(time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+
+		c, err = corpus.NewCorpus(path)
+		require.NoError(t, err)
+		defer c.Close()
+
+		entries := c.Entries()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "first", entries[0].Text)
+		assert.Equal(t, 2, entries[0].Count)
+		assert.True(t, entries[0].LastSeen.Equal(t0.Add(time.Hour)))
+	})
+
+	t.Run("Drops a torn last line so the next record isn't lost", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "corpus.jsonl")
+		c, err := corpus.NewCorpus(path)
+		require.NoError(t, err)
+		_, err = c.Add("first", "catfact", t0)
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+		require.NoError(t, err)
+		_, err = f.WriteString(`{"text": "tor`)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		c, err = corpus.NewCorpus(path)
+		require.NoError(t, err)
+		_, err = c.Add("second", "catfact", t0.Add(time.Minute))
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+
+		c, err = corpus.NewCorpus(path)
+		require.NoError(t, err)
+		defer c.Close()
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("Syncs concurrent adds", func(t *testing.T) {
+		c, err := corpus.NewCorpus(filepath.Join(t.TempDir(), "corpus.jsonl"))
+		require.NoError(t, err)
+		defer c.Close()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := c.Add(fmt.Sprintf("fact %d", i), "catfact", t0)
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, 20, c.Len())
+	})
+
+	t.Run("Exports JSON lines oldest first", func(t *testing.T) {
+		c, err := corpus.NewCorpus("")
+		require.NoError(t, err)
+		_, err = c.Add("later", "catfact", t0.Add(time.Minute))
+		require.NoError(t, err)
+		_, err = c.Add("earlier", "catfact", t0)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, c.Export(&buf))
+
+		dec := json.NewDecoder(&buf)
+		var texts []string
+		for dec.More() {
+			var e corpus.Entry
+			require.NoError(t, dec.Decode(&e))
+			texts = append(texts, e.Text)
+		}
+		assert.Equal(t, []string{"earlier", "later"}, texts)
+	})
+	t.Run("Accepts new facts while an export is being written", func(t *testing.T) {
+		c, err := corpus.NewCorpus("")
+		require.NoError(t, err)
+		_, err = c.Add("some-fact", "catfact", t0)
+		require.NoError(t, err)
+
+		w := &blockingWriter{started: make(chan struct{}), release: make(chan struct{})}
+		done := make(chan error)
+		go func() { done <- c.Export(w) }()
+		<-w.started
+
+		_, err = c.Add("another-fact", "catfact", t0)
+		require.NoError(t, err)
+		close(w.release)
+		require.NoError(t, <-done)
+	})
+}
+
+// blockingWriter blocks its first Write until release is closed.
+type blockingWriter struct {
+	started, release chan struct{}
+	once             sync.Once
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() {
+		close(w.started)
+		<-w.release
+	})
+	return len(p), nil
+}
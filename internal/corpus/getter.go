@@ -0,0 +1,47 @@
+package corpus
+
+import (
+	"context"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"time"
+)
+
+type factGetter struct {
+	next     cat.FactGetter
+	corpus   *Corpus
+	provider string
+}
+
+// NewFactGetter returns a FactGetter that adds every fact next returns to c,
+// so facts served to callers are kept along with harvested ones. Facts are
+// recorded as coming from whichever provider next says served them, or
+// provider if it doesn't say.
+func NewFactGetter(next cat.FactGetter, c *Corpus, provider string) (cat.SourcedFactGetter, error) {
+	if next == nil {
+		return nil, cat.ErrNilParam{Parameter: "FactGetter"}
+	}
+	if c == nil {
+		return nil, cat.ErrNilParam{Parameter: "Corpus"}
+	}
+	return factGetter{next: next, corpus: c, provider: provider}, nil
+}
+
+func (g factGetter) GetFact(ctx context.Context) (cat.Fact, error) {
+	f, _, err := g.GetSourcedFact(ctx)
+	return f, err
+}
+
+func (g factGetter) GetSourcedFact(ctx context.Context) (cat.Fact, string, error) {
+	f, provider, err := cat.GetSourcedFact(ctx, g.next)
+	if err != nil {
+		return "", "", err
+	}
+	if provider == "" {
+		provider = g.provider
+	}
+	if _, err := g.corpus.Add(string(f), provider, time.Now()); err != nil {
+		logging.FromContext(ctx).Error("adding fact to corpus", "error", err)
+	}
+	return f, provider, nil
+}
@@ -0,0 +1,93 @@
+package corpus
+
+import (
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"time"
+)
+
+// Budget limits how hard the Harvester leans on the upstream: at most
+// MaxRequests calls per Period, spaced at least Interval apart.
+type Budget struct {
+	Interval    time.Duration
+	MaxRequests int
+	Period      time.Duration
+}
+
+// Harvester repeatedly asks a FactGetter for facts and adds them to a
+// Corpus.
+type Harvester struct {
+	getter   cat.FactGetter
+	corpus   *Corpus
+	provider string
+	budget   Budget
+	now      func() time.Time
+
+	windowStart time.Time
+	used        int
+}
+
+func NewHarvester(getter cat.FactGetter, c *Corpus, provider string, budget Budget) (*Harvester, error) {
+	if getter == nil {
+		return nil, cat.ErrNilParam{Parameter: "FactGetter"}
+	}
+	if c == nil {
+		return nil, cat.ErrNilParam{Parameter: "Corpus"}
+	}
+	if budget.Interval <= 0 || budget.MaxRequests < 1 || budget.Period <= 0 {
+		return nil, errors.New("budget needs a positive interval, max requests and period")
+	}
+	return &Harvester{
+		getter:   getter,
+		corpus:   c,
+		provider: provider,
+		budget:   budget,
+		now:      time.Now,
+	}, nil
+}
+
+// Run harvests every budget interval until ctx is done.
+func (h *Harvester) Run(ctx context.Context) {
+	t := time.NewTicker(h.budget.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if _, err := h.Harvest(ctx); err != nil && ctx.Err() == nil {
+				logging.FromContext(ctx).Warn("harvesting fact", "provider", h.provider, "error", err)
+			}
+		}
+	}
+}
+
+// ErrBudgetExhausted is returned by Harvest when this period's requests are
+// used up.
+var ErrBudgetExhausted = errors.New("harvest budget exhausted")
+
+// Harvest fetches one fact if the budget allows, and reports whether it was
+// new to the corpus.
+func (h *Harvester) Harvest(ctx context.Context) (bool, error) {
+	now := h.now()
+	if now.Sub(h.windowStart) >= h.budget.Period {
+		h.windowStart = now
+		h.used = 0
+	}
+	if h.used >= h.budget.MaxRequests {
+		return false, ErrBudgetExhausted
+	}
+	h.used++
+
+	f, provider, err := cat.GetSourcedFact(ctx, h.getter)
+	if err != nil {
+		return false, err
+	}
+	if provider == "" {
+		provider = h.provider
+	}
+	return h.corpus.Add(string(f), provider, now)
+}
@@ -0,0 +1,148 @@
+package corpus
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNewHarvester(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c, err := NewCorpus("")
+	require.NoError(t, err)
+	budget := Budget{Interval: time.Second, MaxRequests: 1, Period: time.Hour}
+
+	_, err = NewHarvester(nil, c, "catfact", budget)
+	var e cat.ErrNilParam
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "FactGetter", e.Parameter)
+
+	_, err = NewHarvester(mockcat.NewMockFactGetter(ctrl), c, "catfact", Budget{})
+	assert.Error(t, err)
+}
+
+func TestHarvester_Harvest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stays within the budget and resets each period", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		g := mockcat.NewMockFactGetter(ctrl)
+		gomock.InOrder(
+			g.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("one"), nil),
+			g.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("One!"), nil),
+			g.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("two"), nil),
+		)
+
+		c, err := NewCorpus("")
+		require.NoError(t, err)
+		h, err := NewHarvester(g, c, "catfact", Budget{Interval: time.Second, MaxRequests: 2, Period: time.Hour})
+		require.NoError(t, err)
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		h.now = func() time.Time { return now }
+
+		added, err := h.Harvest(ctx)
+		require.NoError(t, err)
+		assert.True(t, added)
+
+		added, err = h.Harvest(ctx)
+		require.NoError(t, err)
+		assert.False(t, added)
+
+		_, err = h.Harvest(ctx)
+		assert.ErrorIs(t, err, ErrBudgetExhausted)
+
+		now = now.Add(time.Hour)
+		added, err = h.Harvest(ctx)
+		require.NoError(t, err)
+		assert.True(t, added)
+
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("Upstream errors use up budget", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		g := mockcat.NewMockFactGetter(ctrl)
+		g.EXPECT().GetFact(gomock.Any()).Return(cat.Fact(""), errors.New("boom"))
+
+		c, err := NewCorpus("")
+		require.NoError(t, err)
+		h, err := NewHarvester(g, c, "catfact", Budget{Interval: time.Second, MaxRequests: 1, Period: time.Hour})
+		require.NoError(t, err)
+
+		_, err = h.Harvest(ctx)
+		assert.EqualError(t, err, "boom")
+		_, err = h.Harvest(ctx)
+		assert.ErrorIs(t, err, ErrBudgetExhausted)
+		assert.Equal(t, 0, c.Len())
+	})
+}
+
+func TestNewFactGetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c, err := NewCorpus("")
+	require.NoError(t, err)
+
+	_, err = NewFactGetter(nil, c, "catfact")
+	var e cat.ErrNilParam
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "FactGetter", e.Parameter)
+
+	g := mockcat.NewMockFactGetter(ctrl)
+	gomock.InOrder(
+		g.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("Cats purr."), nil),
+		g.EXPECT().GetFact(gomock.Any()).Return(cat.Fact(""), errors.New("boom")),
+	)
+	fg, err := NewFactGetter(g, c, "catfact")
+	require.NoError(t, err)
+
+	f, err := fg.GetFact(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, cat.Fact("Cats purr."), f)
+	_, err = fg.GetFact(context.Background())
+	assert.Error(t, err)
+
+	entries := c.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Cats purr.", entries[0].Text)
+	assert.Equal(t, "catfact", entries[0].Provider)
+}
+
+// sourcedGetter stands in for a router, which says which provider served
+// each fact.
+type sourcedGetter struct {
+	fact     cat.Fact
+	provider string
+}
+
+func (g sourcedGetter) GetFact(ctx context.Context) (cat.Fact, error) {
+	return g.fact, nil
+}
+
+func (g sourcedGetter) GetSourcedFact(ctx context.Context) (cat.Fact, string, error) {
+	return g.fact, g.provider, nil
+}
+
+func TestNewFactGetter_RecordsServingProvider(t *testing.T) {
+	c, err := NewCorpus("")
+	require.NoError(t, err)
+	fg, err := NewFactGetter(sourcedGetter{fact: "Cats purr.", provider: "a"}, c, "fallback")
+	require.NoError(t, err)
+	_, provider, err := cat.GetSourcedFact(context.Background(), fg)
+	require.NoError(t, err)
+	assert.Equal(t, "a", provider)
+	assert.Equal(t, "a", c.Entries()[0].Provider)
+}
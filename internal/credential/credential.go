@@ -0,0 +1,167 @@
+package credential
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Secret is a credential value given inline, or read from an environment
+// variable or a file. In JSON it is either a string or an object with one of
+// "env" or "file".
+type Secret struct {
+	Value string
+	Env   string
+	File  string
+}
+
+func (s *Secret) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &s.Value)
+	}
+	var ref struct {
+		Env  string `json:"env"`
+		File string `json:"file"`
+	}
+	if err := json.Unmarshal(b, &ref); err != nil {
+		return err
+	}
+	if (ref.Env == "") == (ref.File == "") {
+		return errors.New(`secret needs exactly one of "env" or "file"`)
+	}
+	s.Env, s.File = ref.Env, ref.File
+	return nil
+}
+
+// resolve returns the secret's current value, refusing one too short to be
+// redacted from logs safely.
+func (s Secret) resolve() (string, error) {
+	v, err := s.value()
+	if err != nil {
+		return "", err
+	}
+	if len(v) < minSecretLength {
+		return "", fmt.Errorf("shorter than %d characters, too short to redact from logs", minSecretLength)
+	}
+	return v, nil
+}
+
+// value returns the secret's current value. Files are trimmed of trailing
+// newlines, which secret mounts usually have.
+func (s Secret) value() (string, error) {
+	switch {
+	case s.Env != "":
+		v, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		return v, nil
+	case s.File != "":
+		b, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("reading secret: %w", err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+	return s.Value, nil
+}
+
+// Basic is HTTP basic auth.
+type Basic struct {
+	Username string `json:"username"`
+	Password Secret `json:"password"`
+}
+
+// Config is how to authenticate to one upstream. Any combination of fields
+// may be set.
+type Config struct {
+	// APIKeyHeader is the header APIKey is sent in, X-API-Key by default.
+	APIKeyHeader string            `json:"api_key_header"`
+	APIKey       *Secret           `json:"api_key"`
+	BearerToken  *Secret           `json:"bearer_token"`
+	Basic        *Basic            `json:"basic"`
+	Headers      map[string]Secret `json:"headers"`
+}
+
+// Credentials are a Config with its secrets resolved, as headers ready to
+// add to a request.
+type Credentials struct {
+	header http.Header
+	// secrets are every value set, and the encoded basic auth value, to
+	// redact from logs.
+	secrets []string
+}
+
+func (c Config) resolve() (Credentials, error) {
+	h := make(http.Header)
+	var secrets []string
+	for name, s := range c.Headers {
+		v, err := s.resolve()
+		if err != nil {
+			return Credentials{}, fmt.Errorf("header %s: %w", name, err)
+		}
+		h.Set(name, v)
+		secrets = append(secrets, v)
+	}
+	if c.APIKey != nil {
+		v, err := c.APIKey.resolve()
+		if err != nil {
+			return Credentials{}, fmt.Errorf("api key: %w", err)
+		}
+		name := c.APIKeyHeader
+		if name == "" {
+			name = "X-API-Key"
+		}
+		h.Set(name, v)
+		secrets = append(secrets, v)
+	}
+	if c.BearerToken != nil {
+		v, err := c.BearerToken.resolve()
+		if err != nil {
+			return Credentials{}, fmt.Errorf("bearer token: %w", err)
+		}
+		h.Set("Authorization", "Bearer "+v)
+		secrets = append(secrets, v)
+	}
+	if c.Basic != nil {
+		v, err := c.Basic.Password.resolve()
+		if err != nil {
+			return Credentials{}, fmt.Errorf("basic auth password: %w", err)
+		}
+		encoded := base64.StdEncoding.EncodeToString([]byte(c.Basic.Username + ":" + v))
+		h.Set("Authorization", "Basic "+encoded)
+		secrets = append(secrets, v, encoded)
+	}
+	return Credentials{header: h, secrets: secrets}, nil
+}
+
+// Apply sets the credentials' headers on req, replacing any already there.
+func (c Credentials) Apply(req *http.Request) {
+	for name, vs := range c.header {
+		req.Header[name] = append([]string(nil), vs...)
+	}
+}
+
+// files lists the files c's secrets are read from.
+func (c Config) files() []string {
+	var out []string
+	add := func(s *Secret) {
+		if s != nil && s.File != "" {
+			out = append(out, s.File)
+		}
+	}
+	add(c.APIKey)
+	add(c.BearerToken)
+	if c.Basic != nil {
+		add(&c.Basic.Password)
+	}
+	for _, s := range c.Headers {
+		s := s
+		add(&s)
+	}
+	return out
+}
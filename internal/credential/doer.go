@@ -0,0 +1,47 @@
+package credential
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"net/http"
+)
+
+type doer struct {
+	upstream string
+	store    *Store
+	next     cat.Doer
+}
+
+// Doer wraps next so that every request carries upstream's current
+// credentials from store. It should sit closest to the network, so that no
+// other decorator sees them, and errors it returns have every secret
+// redacted.
+func Doer(upstream string, store *Store, next cat.Doer) cat.Doer {
+	return &doer{upstream: upstream, store: store, next: next}
+}
+
+func (d *doer) Do(req *http.Request) (*http.Response, error) {
+	if c, ok := d.store.Credentials(d.upstream); ok {
+		req = req.Clone(req.Context())
+		c.Apply(req)
+	}
+	res, err := d.next.Do(req)
+	if err != nil {
+		return res, &redactedError{msg: d.store.Redact(err.Error()), err: err}
+	}
+	return res, nil
+}
+
+// redactedError hides secrets from the message while keeping the wrapped
+// error for errors.Is and errors.As.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,77 @@
+package credential_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/credential"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestDoer(t *testing.T) {
+	s, err := credential.NewStore(map[string]credential.Config{
+		"image": {APIKey: &credential.Secret{Value: "sekrit-key"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("Adds credentials to a copy of the request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		next := mockcat.NewMockDoer(ctrl)
+		next.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "sekrit-key", req.Header.Get("X-API-Key"))
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		_, err = credential.Doer("image", s, next).Do(req)
+
+		require.NoError(t, err)
+		assert.Empty(t, req.Header.Get("X-API-Key"))
+	})
+
+	t.Run("Leaves other upstreams alone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		next := mockcat.NewMockDoer(ctrl)
+		next.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			assert.Empty(t, req.Header.Get("X-API-Key"))
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		_, err = credential.Doer("fact", s, next).Do(req)
+		require.NoError(t, err)
+	})
+
+	t.Run("Redacts secrets from errors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		next := mockcat.NewMockDoer(ctrl)
+		next.EXPECT().Do(gomock.Any()).Return(nil, &wrapped{msg: "rejected key sekrit-key", err: context.Canceled})
+
+		req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		_, err = credential.Doer("image", s, next).Do(req)
+
+		assert.EqualError(t, err, "rejected key [REDACTED]")
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+type wrapped struct {
+	msg string
+	err error
+}
+
+func (w *wrapped) Error() string { return w.msg }
+func (w *wrapped) Unwrap() error { return w.err }
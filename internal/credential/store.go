@@ -0,0 +1,189 @@
+package credential
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store holds the credentials for each upstream, by name. It also
+// remembers every secret it has ever held, current or rotated out, so they
+// can be redacted from logs and errors.
+type Store struct {
+	path string
+
+	mu       sync.RWMutex
+	configs  map[string]Config
+	creds    map[string]Credentials
+	modTimes map[string]time.Time
+	secrets  map[string]bool
+	// redact is secrets longest first, so a secret containing another is
+	// replaced whole.
+	redact  []string
+	headers map[string]bool
+}
+
+// minSecretLength is the shortest secret accepted. Anything shorter would
+// match too much unrelated text to be redacted, so it is refused rather than
+// left in the logs.
+const minSecretLength = 8
+
+// NewStore returns a Store for the given configs, which are not reloaded.
+func NewStore(configs map[string]Config) (*Store, error) {
+	s := &Store{}
+	if err := s.load(configs); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewFileStore loads a JSON object of upstream name to Config from path.
+func NewFileStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload re-reads the config file and every secret file it names.
+func (s *Store) Reload() error {
+	if s.path == "" {
+		s.mu.RLock()
+		configs := s.configs
+		s.mu.RUnlock()
+		return s.load(configs)
+	}
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("reading upstream credentials: %w", err)
+	}
+	var configs map[string]Config
+	if err := json.Unmarshal(b, &configs); err != nil {
+		return fmt.Errorf("parsing upstream credentials: %w", err)
+	}
+	return s.load(configs)
+}
+
+func (s *Store) load(configs map[string]Config) error {
+	creds := make(map[string]Credentials, len(configs))
+	for name, c := range configs {
+		cr, err := c.resolve()
+		if err != nil {
+			return fmt.Errorf("upstream %s: %w", name, err)
+		}
+		creds[name] = cr
+	}
+	modTimes := s.statFiles(configs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configs = configs
+	s.creds = creds
+	s.modTimes = modTimes
+	if s.secrets == nil {
+		s.secrets = make(map[string]bool)
+		s.headers = make(map[string]bool)
+	}
+	added := false
+	for _, cr := range creds {
+		for name := range cr.header {
+			s.headers[strings.ToLower(name)] = true
+		}
+		for _, v := range cr.secrets {
+			if !s.secrets[v] {
+				s.secrets[v] = true
+				added = true
+			}
+		}
+	}
+	if added {
+		s.redact = make([]string, 0, len(s.secrets))
+		for v := range s.secrets {
+			s.redact = append(s.redact, v)
+		}
+		sort.Slice(s.redact, func(i, j int) bool { return len(s.redact[i]) > len(s.redact[j]) })
+	}
+	return nil
+}
+
+// statFiles returns the modification time of the config file and every
+// secret file.
+func (s *Store) statFiles(configs map[string]Config) map[string]time.Time {
+	out := make(map[string]time.Time)
+	paths := []string{}
+	if s.path != "" {
+		paths = append(paths, s.path)
+	}
+	for _, c := range configs {
+		paths = append(paths, c.files()...)
+	}
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil {
+			out[p] = fi.ModTime()
+		}
+	}
+	return out
+}
+
+// Watch reloads the store whenever the config file or a secret file changes,
+// checking every interval until done is closed.
+func (s *Store) Watch(done <-chan struct{}, interval time.Duration, onError func(error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			s.mu.RLock()
+			changed := false
+			for p, at := range s.modTimes {
+				fi, err := os.Stat(p)
+				if err != nil || !fi.ModTime().Equal(at) {
+					changed = true
+					break
+				}
+			}
+			s.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := s.Reload(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Credentials returns the current credentials for upstream.
+func (s *Store) Credentials(upstream string) (Credentials, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.creds[upstream]
+	return c, ok
+}
+
+// Redact replaces every secret the store has held in str.
+func (s *Store) Redact(str string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.redact {
+		str = strings.ReplaceAll(str, v, logging.Redacted)
+	}
+	return str
+}
+
+// Sensitive reports whether key names a header the store sets.
+func (s *Store) Sensitive(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.headers[strings.ToLower(key)] || strings.EqualFold(key, "authorization")
+}
@@ -0,0 +1,103 @@
+package credential_test
+
+import (
+	"encoding/json"
+	"github.com/matthewjamesboyle/catserver/internal/credential"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func apply(t *testing.T, s *credential.Store, upstream string) http.Header {
+	t.Helper()
+
+	c, ok := s.Credentials(upstream)
+	require.True(t, ok)
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	c.Apply(req)
+	return req.Header
+}
+
+func TestNewStore(t *testing.T) {
+	t.Setenv("CATSERVER_TEST_TOKEN", "env-token")
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("file-key\n"), 0600))
+
+	var configs map[string]credential.Config
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"image": {"api_key_header": "x-api-key", "api_key": {"file": "`+keyFile+`"}, "headers": {"X-Client": "catserver"}},
+		"fact": {"bearer_token": {"env": "CATSERVER_TEST_TOKEN"}},
+		"other": {"basic": {"username": "user", "password": "password"}}
+	}`), &configs))
+
+	s, err := credential.NewStore(configs)
+	require.NoError(t, err)
+
+	h := apply(t, s, "image")
+	assert.Equal(t, "file-key", h.Get("X-API-Key"))
+	assert.Equal(t, "catserver", h.Get("X-Client"))
+	assert.Equal(t, "Bearer env-token", apply(t, s, "fact").Get("Authorization"))
+	assert.Equal(t, "Basic dXNlcjpwYXNzd29yZA==", apply(t, s, "other").Get("Authorization"))
+
+	_, ok := s.Credentials("unknown")
+	assert.False(t, ok)
+}
+
+func TestNewStore_Errors(t *testing.T) {
+	var c credential.Config
+	assert.Error(t, json.Unmarshal([]byte(`{"api_key": {"env": "A", "file": "B"}}`), &c))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"api_key": {"env": "CATSERVER_TEST_UNSET"}}`), &c))
+	_, err := credential.NewStore(map[string]credential.Config{"image": c})
+	assert.EqualError(t, err, "upstream image: api key: environment variable CATSERVER_TEST_UNSET is not set")
+
+	_, err = credential.NewStore(map[string]credential.Config{"image": {APIKey: &credential.Secret{Value: "short"}}})
+	assert.EqualError(t, err, "upstream image: api key: shorter than 8 characters, too short to redact from logs")
+}
+
+func TestStore_Redact(t *testing.T) {
+	s, err := credential.NewStore(map[string]credential.Config{
+		"image":     {APIKey: &credential.Secret{Value: "sekrit-key"}, Headers: map[string]credential.Secret{"X-Client": {Value: "catserver"}}},
+		"fact":      {BearerToken: &credential.Secret{Value: "some-token"}},
+		"translate": {Basic: &credential.Basic{Username: "user", Password: credential.Secret{Value: "password"}}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "calling https://x/?k=[REDACTED] with Bearer [REDACTED]", s.Redact("calling https://x/?k=sekrit-key with Bearer some-token"))
+	assert.Equal(t, "Basic [REDACTED]", s.Redact("Basic dXNlcjpwYXNzd29yZA=="))
+	assert.Equal(t, "[REDACTED] [REDACTED] passed", s.Redact("catserver password passed"), "header values and passwords are redacted too")
+	assert.True(t, s.Sensitive("x-api-key"))
+	assert.True(t, s.Sensitive("Authorization"))
+	assert.False(t, s.Sensitive("error"))
+}
+
+func TestFileStore_Watch(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("old-api-key"), 0600))
+	path := filepath.Join(dir, "upstreams.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"image": {"api_key": {"file": "`+keyFile+`"}}}`), 0600))
+
+	s, err := credential.NewFileStore(path)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	defer close(done)
+	go s.Watch(done, 10*time.Millisecond, func(err error) { t.Error(err) })
+
+	// Make sure the new mtime differs on filesystems with coarse timestamps.
+	require.NoError(t, os.WriteFile(keyFile, []byte("new-api-key"), 0600))
+	require.NoError(t, os.Chtimes(keyFile, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+
+	assert.Eventually(t, func() bool {
+		return apply(t, s, "image").Get("X-API-Key") == "new-api-key"
+	}, time.Second, 10*time.Millisecond)
+
+	// The rotated-out key is still redacted.
+	assert.Equal(t, "[REDACTED] [REDACTED]", s.Redact("old-api-key new-api-key"))
+}
@@ -0,0 +1,181 @@
+package daily
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"os"
+	"sync"
+	"time"
+)
+
+// DateLayout is the format of dates in the archive and the API.
+const DateLayout = "2006-01-02"
+
+var (
+	ErrNotFound   = errors.New("no result archived for that date")
+	ErrFutureDate = errors.New("date is in the future")
+)
+
+// Daily picks one CatResult per calendar day in its time zone and archives
+// it, so everyone sees the same result all day, including across restarts.
+type Daily struct {
+	s          cat.Servicer
+	loc        *time.Location
+	path       string
+	translator cat.ResultTranslator
+	now        func() time.Time
+
+	mu      sync.Mutex
+	archive map[string]cat.CatResult
+}
+
+type Option func(d *Daily)
+
+// WithTranslator translates results for the caller as they are read. The
+// archive itself is kept untranslated.
+func WithTranslator(t cat.ResultTranslator) Option {
+	return func(d *Daily) {
+		d.translator = t
+	}
+}
+
+// NewDaily loads the archive at path, if any. An empty path keeps the
+// archive in memory.
+func NewDaily(s cat.Servicer, loc *time.Location, path string, opts ...Option) (*Daily, error) {
+	if s == nil {
+		return nil, cat.ErrNilParam{Parameter: "Servicer"}
+	}
+	if loc == nil {
+		return nil, cat.ErrNilParam{Parameter: "loc"}
+	}
+
+	d := &Daily{
+		s:       s,
+		loc:     loc,
+		path:    path,
+		now:     time.Now,
+		archive: make(map[string]cat.CatResult),
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	if path == "" {
+		return d, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading daily archive: %w", err)
+	}
+	if err := json.Unmarshal(b, &d.archive); err != nil {
+		return nil, fmt.Errorf("decoding daily archive: %w", err)
+	}
+	return d, nil
+}
+
+// Today returns today's date in the Daily's time zone and its result,
+// selecting and archiving one on the first call of the day.
+func (d *Daily) Today(ctx context.Context) (string, cat.CatResult, error) {
+	date, c, err := d.today(ctx)
+	if err != nil {
+		return "", cat.CatResult{}, err
+	}
+	return date, d.translate(ctx, c), nil
+}
+
+func (d *Daily) today(ctx context.Context) (string, cat.CatResult, error) {
+	date := d.now().In(d.loc).Format(DateLayout)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if c, ok := d.archive[date]; ok {
+		return date, c, nil
+	}
+
+	// The pick is shared by everyone, so it mustn't count against, or be
+	// shaped by, the no-repeat history or languages of whoever asked first.
+	c, err := d.s.GetImageAndFact(cat.WithLanguages(cat.WithSession(ctx, "")))
+	if err != nil {
+		return "", cat.CatResult{}, err
+	}
+	d.archive[date] = c
+	if err := d.save(); err != nil {
+		delete(d.archive, date)
+		return "", cat.CatResult{}, err
+	}
+	return date, c, nil
+}
+
+// Get returns the result for date, a day formatted with DateLayout.
+func (d *Daily) Get(ctx context.Context, date string) (cat.CatResult, error) {
+	day, err := time.ParseInLocation(DateLayout, date, d.loc)
+	if err != nil {
+		return cat.CatResult{}, err
+	}
+
+	today, err := time.ParseInLocation(DateLayout, d.now().In(d.loc).Format(DateLayout), d.loc)
+	if err != nil {
+		return cat.CatResult{}, err
+	}
+	switch {
+	case day.After(today):
+		return cat.CatResult{}, ErrFutureDate
+	case day.Equal(today):
+		_, c, err := d.Today(ctx)
+		return c, err
+	}
+
+	d.mu.Lock()
+	c, ok := d.archive[date]
+	d.mu.Unlock()
+	if !ok {
+		return cat.CatResult{}, ErrNotFound
+	}
+	return d.translate(ctx, c), nil
+}
+
+func (d *Daily) translate(ctx context.Context, c cat.CatResult) cat.CatResult {
+	if d.translator == nil {
+		return c
+	}
+	return d.translator.TranslateResult(ctx, c)
+}
+
+// NextMidnight returns the start of the day after t in the Daily's time
+// zone, when today's result expires.
+func (d *Daily) NextMidnight(t time.Time) time.Time {
+	t = t.In(d.loc)
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, d.loc)
+}
+
+// Now returns the clock reading used to decide which day it is.
+func (d *Daily) Now() time.Time {
+	return d.now()
+}
+
+// save writes the archive atomically. Callers hold mu.
+func (d *Daily) save() error {
+	if d.path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(d.archive)
+	if err != nil {
+		return err
+	}
+	tmp := d.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("writing daily archive: %w", err)
+	}
+	if err := os.Rename(tmp, d.path); err != nil {
+		return fmt.Errorf("replacing daily archive: %w", err)
+	}
+	return nil
+}
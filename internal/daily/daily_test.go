@@ -0,0 +1,175 @@
+package daily
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewDaily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, err := NewDaily(nil, time.UTC, "")
+	var e cat.ErrNilParam
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "Servicer", e.Parameter)
+
+	_, err = NewDaily(mockcat.NewMockServicer(ctrl), nil, "")
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "loc", e.Parameter)
+}
+
+func TestDaily(t *testing.T) {
+	ctx := context.Background()
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	t.Run("Selects once per day in the configured time zone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		gomock.InOrder(
+			s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{Fact: "first"}, nil),
+			s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{Fact: "second"}, nil),
+		)
+
+		d, err := NewDaily(s, tokyo, "")
+		require.NoError(t, err)
+		// 14:00 UTC is 23:00 in Tokyo.
+		now := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+		d.now = func() time.Time { return now }
+
+		date, c, err := d.Today(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "2026-03-01", date)
+		assert.Equal(t, cat.Fact("first"), c.Fact)
+
+		now = now.Add(30 * time.Minute)
+		_, c, err = d.Today(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, cat.Fact("first"), c.Fact)
+
+		now = now.Add(time.Hour)
+		date, c, err = d.Today(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "2026-03-02", date)
+		assert.Equal(t, cat.Fact("second"), c.Fact)
+
+		c, err = d.Get(ctx, "2026-03-01")
+		require.NoError(t, err)
+		assert.Equal(t, cat.Fact("first"), c.Fact)
+	})
+
+	t.Run("Keeps the day's result across restarts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		path := filepath.Join(t.TempDir(), "daily.json")
+		s := mockcat.NewMockServicer(ctrl)
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{Fact: "first"}, nil).Times(1)
+
+		d, err := NewDaily(s, time.UTC, path)
+		require.NoError(t, err)
+		_, _, err = d.Today(ctx)
+		require.NoError(t, err)
+
+		d, err = NewDaily(s, time.UTC, path)
+		require.NoError(t, err)
+		_, c, err := d.Today(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, cat.Fact("first"), c.Fact)
+	})
+
+	t.Run("Does not archive a failed selection", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		gomock.InOrder(
+			s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{}, errors.New("some-error")),
+			s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{Fact: "first"}, nil),
+		)
+
+		d, err := NewDaily(s, time.UTC, "")
+		require.NoError(t, err)
+
+		_, _, err = d.Today(ctx)
+		assert.Error(t, err)
+		_, c, err := d.Today(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, cat.Fact("first"), c.Fact)
+	})
+
+	t.Run("Picks without the caller's languages and translates for each caller", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		s.EXPECT().GetImageAndFact(gomock.Any()).DoAndReturn(func(ctx context.Context) (cat.CatResult, error) {
+			assert.Empty(t, cat.LanguagesFromContext(ctx))
+			return cat.CatResult{Fact: "first"}, nil
+		})
+		tr := translatorFunc(func(ctx context.Context, c cat.CatResult) cat.CatResult {
+			if langs := cat.LanguagesFromContext(ctx); len(langs) > 0 {
+				c.Translation = &cat.Translation{Lang: langs[0], Fact: c.Fact + " in " + cat.Fact(langs[0])}
+			}
+			return c
+		})
+
+		d, err := NewDaily(s, time.UTC, "", WithTranslator(tr))
+		require.NoError(t, err)
+
+		_, c, err := d.Today(cat.WithLanguages(ctx, "de"))
+		require.NoError(t, err)
+		assert.Equal(t, &cat.Translation{Lang: "de", Fact: "first in de"}, c.Translation)
+
+		_, c, err = d.Today(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, c.Translation)
+	})
+
+	t.Run("Rejects future and unknown dates", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		d, err := NewDaily(mockcat.NewMockServicer(ctrl), time.UTC, "")
+		require.NoError(t, err)
+		d.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+		_, err = d.Get(ctx, "2026-03-02")
+		assert.True(t, errors.Is(err, ErrFutureDate))
+		_, err = d.Get(ctx, "2026-02-01")
+		assert.True(t, errors.Is(err, ErrNotFound))
+		_, err = d.Get(ctx, "yesterday")
+		assert.Error(t, err)
+	})
+}
+
+type translatorFunc func(ctx context.Context, c cat.CatResult) cat.CatResult
+
+func (f translatorFunc) TranslateResult(ctx context.Context, c cat.CatResult) cat.CatResult {
+	return f(ctx, c)
+}
+
+func TestDaily_NextMidnight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	d, err := NewDaily(mockcat.NewMockServicer(ctrl), tokyo, "")
+	require.NoError(t, err)
+
+	got := d.NextMidnight(time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC), got.UTC())
+}
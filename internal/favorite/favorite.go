@@ -0,0 +1,67 @@
+package favorite
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"sort"
+	"time"
+)
+
+// DefaultLimit and MaxLimit bound the page size of List.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrNotFound = errors.New("favorite not found")
+
+type Favorite struct {
+	ID        string        `json:"id"`
+	Owner     string        `json:"-"`
+	Result    cat.CatResult `json:"result"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// FavoriteStore keeps each owner's saved CatResults. List returns favorites
+// oldest first, starting after cursor, and the cursor for the next page or
+// "" on the last page.
+type FavoriteStore interface {
+	Save(ctx context.Context, owner string, c cat.CatResult) (Favorite, error)
+	List(ctx context.Context, owner, cursor string, limit int) ([]Favorite, string, error)
+	Delete(ctx context.Context, owner, id string) error
+}
+
+// newID returns an ID that sorts in creation order, so it doubles as a
+// pagination cursor.
+func newID(t time.Time) string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%016x%s", t.UnixNano(), hex.EncodeToString(b))
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
+
+// page returns up to limit favorites after cursor from favs, which must be
+// sorted by ID.
+func page(favs []Favorite, cursor string, limit int) ([]Favorite, string) {
+	limit = clampLimit(limit)
+	start := sort.Search(len(favs), func(i int) bool { return favs[i].ID > cursor })
+
+	end := start + limit
+	if end >= len(favs) {
+		return append([]Favorite(nil), favs[start:]...), ""
+	}
+	return append([]Favorite(nil), favs[start:end]...), favs[end-1].ID
+}
@@ -0,0 +1,232 @@
+package favorite
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"os"
+	"sync"
+	"time"
+)
+
+// compactMinRecords stops small logs being rewritten on every delete.
+const compactMinRecords = 1000
+
+const (
+	opSave   = "save"
+	opDelete = "delete"
+)
+
+type record struct {
+	Op        string         `json:"op"`
+	ID        string         `json:"id"`
+	Owner     string         `json:"owner"`
+	Result    *cat.CatResult `json:"result,omitempty"`
+	CreatedAt time.Time      `json:"created_at,omitempty"`
+}
+
+// FileStore is a FavoriteStore backed by an append-only log of saves and
+// deletes, replayed into memory on start. Once most of the log is dead
+// records it is compacted by rewriting only the live favorites.
+type FileStore struct {
+	path string
+	mem  *MemoryStore
+
+	mu      sync.Mutex
+	f       *os.File
+	records int
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, cat.ErrNilParam{Parameter: "path"}
+	}
+
+	s := &FileStore{path: path, mem: NewMemoryStore()}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening favorites log: %w", err)
+	}
+	s.f = f
+	return s, nil
+}
+
+func (s *FileStore) replay() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening favorites log: %w", err)
+	}
+	defer f.Close()
+
+	var valid int64
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var r record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			// A torn write from a crash can only be the last line; drop
+			// it and everything after.
+			break
+		}
+		s.apply(r)
+		s.records++
+		valid += int64(len(sc.Bytes())) + 1
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("reading favorites log: %w", err)
+	}
+
+	if fi, err := f.Stat(); err == nil && fi.Size() > valid {
+		if err := os.Truncate(s.path, valid); err != nil {
+			return fmt.Errorf("truncating favorites log: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) apply(r record) {
+	switch r.Op {
+	case opSave:
+		if r.Result != nil {
+			s.mem.put(Favorite{ID: r.ID, Owner: r.Owner, Result: *r.Result, CreatedAt: r.CreatedAt})
+		}
+	case opDelete:
+		s.mem.remove(r.Owner, r.ID)
+	}
+}
+
+func (s *FileStore) append(r record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("writing favorites log: %w", err)
+	}
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("syncing favorites log: %w", err)
+	}
+	s.records++
+	return nil
+}
+
+func (s *FileStore) Save(_ context.Context, owner string, c cat.CatResult) (Favorite, error) {
+	now := s.mem.now()
+	fav := Favorite{ID: newID(now), Owner: owner, Result: c, CreatedAt: now}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.append(record{Op: opSave, ID: fav.ID, Owner: owner, Result: &c, CreatedAt: now}); err != nil {
+		return Favorite{}, err
+	}
+	s.mem.mu.Lock()
+	s.mem.put(fav)
+	s.mem.mu.Unlock()
+	return fav, nil
+}
+
+func (s *FileStore) List(ctx context.Context, owner, cursor string, limit int) ([]Favorite, string, error) {
+	return s.mem.List(ctx, owner, cursor, limit)
+}
+
+func (s *FileStore) Delete(_ context.Context, owner, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// As with Save, the log comes first, so a failed write leaves memory
+	// agreeing with what a restart would replay. Holding mu means nothing
+	// else can remove it in between.
+	s.mem.mu.RLock()
+	found := s.mem.has(owner, id)
+	s.mem.mu.RUnlock()
+	if !found {
+		return ErrNotFound
+	}
+
+	if err := s.append(record{Op: opDelete, ID: id, Owner: owner}); err != nil {
+		return err
+	}
+	s.mem.mu.Lock()
+	s.mem.remove(owner, id)
+	s.mem.mu.Unlock()
+	return s.maybeCompact()
+}
+
+// maybeCompact rewrites the log once less than half of it is live. Callers
+// hold mu.
+func (s *FileStore) maybeCompact() error {
+	s.mem.mu.RLock()
+	live := s.mem.len()
+	s.mem.mu.RUnlock()
+
+	if s.records < compactMinRecords || s.records < 2*live {
+		return nil
+	}
+	return s.compact()
+}
+
+// Compact rewrites the log so it holds only live favorites.
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compact()
+}
+
+func (s *FileStore) compact() error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("creating compacted log: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	var n int
+	s.mem.mu.RLock()
+	for owner, favs := range s.mem.byOwner {
+		for _, fav := range favs {
+			res := fav.Result
+			if err := enc.Encode(record{Op: opSave, ID: fav.ID, Owner: owner, Result: &res, CreatedAt: fav.CreatedAt}); err != nil {
+				s.mem.mu.RUnlock()
+				f.Close()
+				return err
+			}
+			n++
+		}
+	}
+	s.mem.mu.RUnlock()
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("writing compacted log: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing compacted log: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		f.Close()
+		return fmt.Errorf("replacing favorites log: %w", err)
+	}
+
+	_ = s.f.Close()
+	s.f = f
+	s.records = n
+	return nil
+}
+
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
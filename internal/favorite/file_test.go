@@ -0,0 +1,142 @@
+package favorite_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/favorite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var n int
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		n++
+	}
+	return n
+}
+
+func TestNewFileStore(t *testing.T) {
+	s, err := favorite.NewFileStore("")
+
+	assert.Nil(t, s)
+	var e cat.ErrNilParam
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "path", e.Parameter)
+}
+
+func TestFileStore(t *testing.T) {
+	testStore(t, func(t *testing.T) favorite.FavoriteStore {
+		s, err := favorite.NewFileStore(filepath.Join(t.TempDir(), "favorites.log"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	})
+
+	ctx := context.Background()
+	someResult := cat.CatResult{ImageURL: "http://someurl", Fact: "some-fact"}
+
+	t.Run("Survives a restart", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "favorites.log")
+		s, err := favorite.NewFileStore(path)
+		require.NoError(t, err)
+
+		kept, err := s.Save(ctx, "alice", someResult)
+		require.NoError(t, err)
+		gone, err := s.Save(ctx, "alice", someResult)
+		require.NoError(t, err)
+		require.NoError(t, s.Delete(ctx, "alice", gone.ID))
+		require.NoError(t, s.Close())
+
+		s, err = favorite.NewFileStore(path)
+		require.NoError(t, err)
+		defer s.Close()
+
+		favs, _, err := s.List(ctx, "alice", "", 10)
+		require.NoError(t, err)
+		require.Len(t, favs, 1)
+		assert.Equal(t, kept.ID, favs[0].ID)
+		assert.Equal(t, someResult, favs[0].Result)
+	})
+
+	t.Run("Drops a torn final record", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "favorites.log")
+		s, err := favorite.NewFileStore(path)
+		require.NoError(t, err)
+		_, err = s.Save(ctx, "alice", someResult)
+		require.NoError(t, err)
+		require.NoError(t, s.Close())
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+		require.NoError(t, err)
+		_, err = f.WriteString(`{"op":"save","id":"tor`)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		s, err = favorite.NewFileStore(path)
+		require.NoError(t, err)
+		_, err = s.Save(ctx, "alice", someResult)
+		require.NoError(t, err)
+		require.NoError(t, s.Close())
+
+		s, err = favorite.NewFileStore(path)
+		require.NoError(t, err)
+		defer s.Close()
+		favs, _, err := s.List(ctx, "alice", "", 10)
+		require.NoError(t, err)
+		assert.Len(t, favs, 2)
+	})
+
+	t.Run("Compacts away deleted favorites", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "favorites.log")
+		s, err := favorite.NewFileStore(path)
+		require.NoError(t, err)
+		defer s.Close()
+
+		kept, err := s.Save(ctx, "alice", someResult)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			f, err := s.Save(ctx, "alice", someResult)
+			require.NoError(t, err)
+			require.NoError(t, s.Delete(ctx, "alice", f.ID))
+		}
+		require.Equal(t, 7, countLines(t, path))
+
+		require.NoError(t, s.Compact())
+		assert.Equal(t, 1, countLines(t, path))
+
+		_, err = s.Save(ctx, "alice", someResult)
+		require.NoError(t, err)
+		assert.Equal(t, 2, countLines(t, path))
+
+		favs, _, err := s.List(ctx, "alice", "", 10)
+		require.NoError(t, err)
+		require.Len(t, favs, 2)
+		assert.Equal(t, kept.ID, favs[0].ID)
+	})
+	t.Run("Keeps a favorite whose delete couldn't be logged", func(t *testing.T) {
+		s, err := favorite.NewFileStore(filepath.Join(t.TempDir(), "favorites.log"))
+		require.NoError(t, err)
+		f, err := s.Save(ctx, "alice", someResult)
+		require.NoError(t, err)
+		require.NoError(t, s.Close())
+
+		assert.Error(t, s.Delete(ctx, "alice", f.ID))
+
+		favs, _, err := s.List(ctx, "alice", "", 10)
+		require.NoError(t, err)
+		assert.Len(t, favs, 1)
+	})
+}
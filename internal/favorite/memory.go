@@ -0,0 +1,92 @@
+package favorite
+
+import (
+	"context"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a FavoriteStore that forgets everything on restart.
+type MemoryStore struct {
+	now func() time.Time
+
+	mu      sync.RWMutex
+	byOwner map[string][]Favorite
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		byOwner: make(map[string][]Favorite),
+	}
+}
+
+func (s *MemoryStore) Save(_ context.Context, owner string, c cat.CatResult) (Favorite, error) {
+	now := s.now()
+	f := Favorite{ID: newID(now), Owner: owner, Result: c, CreatedAt: now}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(f)
+	return f, nil
+}
+
+func (s *MemoryStore) List(_ context.Context, owner, cursor string, limit int) ([]Favorite, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	favs, next := page(s.byOwner[owner], cursor, limit)
+	return favs, next, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, owner, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.remove(owner, id) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// put and remove keep each owner's favorites sorted by ID. Callers hold mu.
+func (s *MemoryStore) put(f Favorite) {
+	favs := s.byOwner[f.Owner]
+	i := sort.Search(len(favs), func(i int) bool { return favs[i].ID >= f.ID })
+	favs = append(favs, Favorite{})
+	copy(favs[i+1:], favs[i:])
+	favs[i] = f
+	s.byOwner[f.Owner] = favs
+}
+
+func (s *MemoryStore) remove(owner, id string) bool {
+	favs := s.byOwner[owner]
+	i := sort.Search(len(favs), func(i int) bool { return favs[i].ID >= id })
+	if i == len(favs) || favs[i].ID != id {
+		return false
+	}
+	favs = append(favs[:i], favs[i+1:]...)
+	if len(favs) == 0 {
+		delete(s.byOwner, owner)
+	} else {
+		s.byOwner[owner] = favs
+	}
+	return true
+}
+
+// has reports whether owner has a favorite with id. Callers hold mu.
+func (s *MemoryStore) has(owner, id string) bool {
+	favs := s.byOwner[owner]
+	i := sort.Search(len(favs), func(i int) bool { return favs[i].ID >= id })
+	return i < len(favs) && favs[i].ID == id
+}
+
+func (s *MemoryStore) len() int {
+	var n int
+	for _, favs := range s.byOwner {
+		n += len(favs)
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+package favorite_test
+
+import (
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/favorite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// testStore runs the behaviour every FavoriteStore must share.
+func testStore(t *testing.T, newStore func(t *testing.T) favorite.FavoriteStore) {
+	ctx := context.Background()
+	someResult := cat.CatResult{ImageURL: "http://someurl", Fact: "some-fact"}
+
+	t.Run("Saves and lists favorites per owner", func(t *testing.T) {
+		s := newStore(t)
+
+		f, err := s.Save(ctx, "alice", someResult)
+		require.NoError(t, err)
+		assert.NotEmpty(t, f.ID)
+		assert.Equal(t, someResult, f.Result)
+
+		_, err = s.Save(ctx, "bob", someResult)
+		require.NoError(t, err)
+
+		favs, next, err := s.List(ctx, "alice", "", 10)
+		require.NoError(t, err)
+		assert.Empty(t, next)
+		require.Len(t, favs, 1)
+		assert.Equal(t, f.ID, favs[0].ID)
+	})
+
+	t.Run("Paginates with a cursor", func(t *testing.T) {
+		s := newStore(t)
+
+		var ids []string
+		for i := 0; i < 5; i++ {
+			f, err := s.Save(ctx, "alice", someResult)
+			require.NoError(t, err)
+			ids = append(ids, f.ID)
+		}
+
+		var got []string
+		cursor := ""
+		for pages := 0; pages < 10; pages++ {
+			favs, next, err := s.List(ctx, "alice", cursor, 2)
+			require.NoError(t, err)
+			for _, f := range favs {
+				got = append(got, f.ID)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		assert.Equal(t, ids, got)
+	})
+
+	t.Run("Deletes only the owner's favorite", func(t *testing.T) {
+		s := newStore(t)
+
+		f, err := s.Save(ctx, "alice", someResult)
+		require.NoError(t, err)
+
+		err = s.Delete(ctx, "bob", f.ID)
+		assert.True(t, errors.Is(err, favorite.ErrNotFound))
+
+		require.NoError(t, s.Delete(ctx, "alice", f.ID))
+		favs, _, err := s.List(ctx, "alice", "", 10)
+		require.NoError(t, err)
+		assert.Empty(t, favs)
+
+		err = s.Delete(ctx, "alice", f.ID)
+		assert.True(t, errors.Is(err, favorite.ErrNotFound))
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) favorite.FavoriteStore {
+		return favorite.NewMemoryStore()
+	})
+}
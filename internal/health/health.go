@@ -0,0 +1,218 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds a single probe when Probe.Timeout is zero.
+const DefaultTimeout = 2 * time.Second
+
+// Probe checks a single upstream with a GET through its Doer. The upstream
+// counts as up if it answers with a non 5xx status. If Check is set, it is
+// called instead, such as a Recorder's for an upstream that shouldn't be
+// called just to see if it's up.
+type Probe struct {
+	Name string
+	// Group names upstreams that stand in for each other, such as the
+	// providers requests are routed between. The server is ready while any
+	// probe in a group is up. Probes without a Group are their own.
+	Group   string
+	URL     string
+	Doer    cat.Doer
+	Check   func(ctx context.Context) error
+	Timeout time.Duration
+}
+
+type Result struct {
+	Status    string    `json:"status"`
+	LatencyMS int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+const (
+	statusUp   = "up"
+	statusDown = "down"
+)
+
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker serves /healthz and /readyz. Probe results are cached for ttl so
+// frequent readiness checks don't hammer the upstreams.
+type Checker struct {
+	probes   []Probe
+	ttl      time.Duration
+	now      func() time.Time
+	onLookup func(hit bool)
+	draining int32
+
+	mu      sync.Mutex
+	results map[string]Result
+	expires time.Time
+}
+
+func NewChecker(ttl time.Duration, probes ...Probe) (*Checker, error) {
+	for _, p := range probes {
+		if p.Name == "" {
+			return nil, errors.New("probes need a name")
+		}
+		if p.Check != nil {
+			continue
+		}
+		if p.Doer == nil {
+			return nil, cat.ErrNilParam{Parameter: "Doer"}
+		}
+		if p.URL == "" {
+			return nil, errors.New("probes need a url or a check")
+		}
+	}
+	return &Checker{probes: probes, ttl: ttl, now: time.Now, onLookup: func(bool) {}}, nil
+}
+
+// OnCacheLookup calls onLookup on every Check, with whether the cached
+// results were served rather than re-running the probes.
+func (c *Checker) OnCacheLookup(onLookup func(hit bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onLookup = onLookup
+}
+
+// Drain marks the server not ready, so load balancers stop routing to it
+// before it shuts down.
+func (c *Checker) Drain() {
+	atomic.StoreInt32(&c.draining, 1)
+}
+
+func (c *Checker) Draining() bool {
+	return atomic.LoadInt32(&c.draining) == 1
+}
+
+// Check returns the probe results, re-running the probes if the cached ones
+// have expired.
+func (c *Checker) Check(ctx context.Context) map[string]Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.results != nil && c.now().Before(c.expires) {
+		c.onLookup(true)
+		return c.results
+	}
+	c.onLookup(false)
+
+	results := make(map[string]Result, len(c.probes))
+	var rmu sync.Mutex
+	var wg sync.WaitGroup
+	for _, p := range c.probes {
+		wg.Add(1)
+		go func(p Probe) {
+			defer wg.Done()
+			r := c.probe(ctx, p)
+			rmu.Lock()
+			results[p.Name] = r
+			rmu.Unlock()
+		}(p)
+	}
+	wg.Wait()
+
+	c.results = results
+	c.expires = c.now().Add(c.ttl)
+	return results
+}
+
+func (c *Checker) probe(ctx context.Context, p Probe) Result {
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	// The result is shared with other callers, so don't let this caller
+	// going away fail the probe.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	start := c.now()
+	r := Result{Status: statusUp, CheckedAt: start}
+
+	err := func() error {
+		if p.Check != nil {
+			return p.Check(ctx)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+		if err != nil {
+			return err
+		}
+		res, err := p.Doer.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		_, _ = io.Copy(ioutil.Discard, res.Body)
+		if res.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("status %d", res.StatusCode)
+		}
+		return nil
+	}()
+
+	r.LatencyMS = c.now().Sub(start).Milliseconds()
+	if err != nil {
+		r.Status = statusDown
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// Liveness reports that the process is up. It never checks dependencies.
+func (c *Checker) Liveness(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, http.StatusOK, Report{Status: "ok"})
+}
+
+// Readiness reports 200 only if at least one probe in every group is up and
+// the server isn't draining.
+func (c *Checker) Readiness(w http.ResponseWriter, req *http.Request) {
+	if c.Draining() {
+		writeReport(w, http.StatusServiceUnavailable, Report{Status: "draining"})
+		return
+	}
+
+	rep := Report{Status: "ready", Checks: c.Check(req.Context())}
+	up := make(map[string]bool)
+	for _, p := range c.probes {
+		g := p.Group
+		if g == "" {
+			g = p.Name
+		}
+		up[g] = up[g] || rep.Checks[p.Name].Status == statusUp
+	}
+	code := http.StatusOK
+	for _, ok := range up {
+		if !ok {
+			rep.Status = "not_ready"
+			code = http.StatusServiceUnavailable
+		}
+	}
+	writeReport(w, code, rep)
+}
+
+func writeReport(w http.ResponseWriter, code int, rep Report) {
+	b, err := json.Marshal(rep)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,224 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func okResponse() *http.Response {
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString("{}"))}
+}
+
+func TestNewChecker(t *testing.T) {
+	t.Run("Returns an error given a probe without a Doer", func(t *testing.T) {
+		c, err := NewChecker(time.Second, Probe{Name: "fact", URL: "http://some-url"})
+
+		assert.Nil(t, c)
+		assert.Error(t, err)
+	})
+
+	t.Run("Returns an error given a probe without a url", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, err := NewChecker(time.Second, Probe{Name: "fact", Doer: mockcat.NewMockDoer(ctrl)})
+
+		assert.Nil(t, c)
+		assert.Error(t, err)
+	})
+}
+
+func TestChecker_Check(t *testing.T) {
+	t.Run("Caches results until the ttl expires", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		d := mockcat.NewMockDoer(ctrl)
+		d.EXPECT().Do(gomock.Any()).DoAndReturn(func(*http.Request) (*http.Response, error) {
+			return okResponse(), nil
+		}).Times(2)
+
+		c, err := NewChecker(time.Minute, Probe{Name: "fact", URL: "http://some-url", Doer: d})
+		require.NoError(t, err)
+		now := time.Unix(0, 0)
+		c.now = func() time.Time { return now }
+		var lookups []bool
+		c.OnCacheLookup(func(hit bool) { lookups = append(lookups, hit) })
+
+		assert.Equal(t, statusUp, c.Check(context.Background())["fact"].Status)
+		c.Check(context.Background())
+
+		now = now.Add(time.Minute)
+		c.Check(context.Background())
+		assert.Equal(t, []bool{false, true, false}, lookups)
+	})
+
+	t.Run("Reports a probe down given an error or a 5xx", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fact := mockcat.NewMockDoer(ctrl)
+		fact.EXPECT().Do(gomock.Any()).Return(nil, errors.New("some-error"))
+		image := mockcat.NewMockDoer(ctrl)
+		image.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: http.StatusBadGateway,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+		}, nil)
+
+		c, err := NewChecker(time.Minute,
+			Probe{Name: "fact", URL: "http://some-url", Doer: fact},
+			Probe{Name: "image", URL: "http://some-other-url", Doer: image},
+		)
+		require.NoError(t, err)
+
+		res := c.Check(context.Background())
+
+		assert.Equal(t, statusDown, res["fact"].Status)
+		assert.Equal(t, "some-error", res["fact"].Error)
+		assert.Equal(t, statusDown, res["image"].Status)
+		assert.Equal(t, "status 502", res["image"].Error)
+	})
+
+	t.Run("Applies the probe timeout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		d := mockcat.NewMockDoer(ctrl)
+		d.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+
+		c, err := NewChecker(time.Minute, Probe{Name: "fact", URL: "http://some-url", Doer: d, Timeout: 10 * time.Millisecond})
+		require.NoError(t, err)
+
+		assert.Equal(t, statusDown, c.Check(context.Background())["fact"].Status)
+	})
+}
+
+func TestChecker_Check_Recorder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var r Recorder
+	d := mockcat.NewMockDoer(ctrl)
+	gomock.InOrder(
+		d.EXPECT().Do(gomock.Any()).Return(nil, errors.New("some-error")),
+		d.EXPECT().Do(gomock.Any()).Return(nil, context.Canceled),
+		d.EXPECT().Do(gomock.Any()).Return(okResponse(), nil),
+	)
+	c, err := NewChecker(0, Probe{Name: "image", Check: r.Check})
+	require.NoError(t, err)
+
+	assert.Equal(t, statusUp, c.Check(context.Background())["image"].Status, "up until a call says otherwise")
+
+	req := httptest.NewRequest(http.MethodGet, "http://some-url", nil)
+	_, _ = r.Doer(d).Do(req)
+	assert.Equal(t, "some-error", c.Check(context.Background())["image"].Error)
+	_, _ = r.Doer(d).Do(req)
+	assert.Equal(t, statusDown, c.Check(context.Background())["image"].Status, "cancelled calls are ignored")
+	_, _ = r.Doer(d).Do(req)
+	assert.Equal(t, statusUp, c.Check(context.Background())["image"].Status)
+}
+
+func TestChecker_Readiness(t *testing.T) {
+	t.Run("Returns 200 with per dependency status", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		d := mockcat.NewMockDoer(ctrl)
+		d.EXPECT().Do(gomock.Any()).Return(okResponse(), nil)
+
+		c, err := NewChecker(time.Minute, Probe{Name: "fact", URL: "http://some-url", Doer: d})
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		c.Readiness(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var rep Report
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&rep))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "ready", rep.Status)
+		assert.Equal(t, statusUp, rep.Checks["fact"].Status)
+	})
+
+	t.Run("Returns 503 given a dependency is down", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		d := mockcat.NewMockDoer(ctrl)
+		d.EXPECT().Do(gomock.Any()).Return(nil, errors.New("some-error"))
+
+		c, err := NewChecker(time.Minute, Probe{Name: "fact", URL: "http://some-url", Doer: d})
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		c.Readiness(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+
+	t.Run("Returns 200 while one probe in each group is up", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		up := mockcat.NewMockDoer(ctrl)
+		up.EXPECT().Do(gomock.Any()).DoAndReturn(func(*http.Request) (*http.Response, error) {
+			return okResponse(), nil
+		}).Times(2)
+		down := mockcat.NewMockDoer(ctrl)
+		down.EXPECT().Do(gomock.Any()).Return(nil, errors.New("some-error")).Times(3)
+
+		probes := []Probe{
+			{Name: "fact:a", Group: "fact", URL: "http://a", Doer: up},
+			{Name: "fact:b", Group: "fact", URL: "http://b", Doer: down},
+		}
+		c, err := NewChecker(0, probes...)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		c.Readiness(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		c, err = NewChecker(0, append(probes, Probe{Name: "image", URL: "http://c", Doer: down})...)
+		require.NoError(t, err)
+		rr = httptest.NewRecorder()
+		c.Readiness(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+
+	t.Run("Returns 503 without probing once draining", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c, err := NewChecker(time.Minute, Probe{Name: "fact", URL: "http://some-url", Doer: mockcat.NewMockDoer(ctrl)})
+		require.NoError(t, err)
+		c.Drain()
+
+		rr := httptest.NewRecorder()
+		c.Readiness(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.True(t, c.Draining())
+	})
+}
+
+func TestChecker_Liveness(t *testing.T) {
+	c, err := NewChecker(time.Minute)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	c.Liveness(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
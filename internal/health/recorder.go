@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"net/http"
+	"sync"
+)
+
+// Recorder remembers how the last real call to an upstream went, so the
+// upstream's readiness can be judged without calling it just to check.
+// That matters for upstreams with a quota, which probes would use up.
+type Recorder struct {
+	mu   sync.Mutex
+	last error
+}
+
+// Doer wraps next so that every call's outcome is recorded.
+func (r *Recorder) Doer(next cat.Doer) cat.Doer {
+	return recordingDoer{r: r, next: next}
+}
+
+// Check returns the error from the last call, or nil if it succeeded or
+// there hasn't been one yet.
+func (r *Recorder) Check(context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+func (r *Recorder) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last = err
+}
+
+type recordingDoer struct {
+	r    *Recorder
+	next cat.Doer
+}
+
+func (d recordingDoer) Do(req *http.Request) (*http.Response, error) {
+	res, err := d.next.Do(req)
+	switch {
+	case errors.Is(err, context.Canceled):
+		// The caller went away, which says nothing about the upstream.
+	case err != nil:
+		d.r.record(err)
+	case res.StatusCode >= http.StatusInternalServerError:
+		d.r.record(fmt.Errorf("status %d", res.StatusCode))
+	default:
+		d.r.record(nil)
+	}
+	return res, err
+}
@@ -0,0 +1,159 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Duration is a time.Duration written in JSON as a string like "30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Config tunes the *http.Client for one upstream. Zero fields take the
+// values in DefaultConfig.
+type Config struct {
+	// Timeout bounds a whole call, including reading the body.
+	Timeout Duration `json:"timeout"`
+
+	MaxIdleConns        int      `json:"max_idle_conns"`
+	MaxIdleConnsPerHost int      `json:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int      `json:"max_conns_per_host"`
+	IdleConnTimeout     Duration `json:"idle_conn_timeout"`
+	DialTimeout         Duration `json:"dial_timeout"`
+	KeepAlive           Duration `json:"keep_alive"`
+	TLSHandshakeTimeout Duration `json:"tls_handshake_timeout"`
+	DisableKeepAlives   bool     `json:"disable_keep_alives"`
+	DisableHTTP2        bool     `json:"disable_http2"`
+
+	// Proxy is a proxy URL, or "none" to connect directly. Empty uses the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string     `json:"proxy"`
+	TLS   *TLSConfig `json:"tls"`
+}
+
+// TLSConfig customises how upstreams are verified, and the client
+// certificate presented to them.
+type TLSConfig struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile string `json:"ca_file"`
+	// CertFile and KeyFile are a client certificate, for mutual TLS.
+	CertFile   string `json:"cert_file"`
+	KeyFile    string `json:"key_file"`
+	ServerName string `json:"server_name"`
+	// MinVersion is "1.2" or "1.3". Defaults to 1.2.
+	MinVersion         string `json:"min_version"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// DefaultConfig is used for any field left unset.
+var DefaultConfig = Config{
+	Timeout:             Duration(10 * time.Second),
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 20,
+	IdleConnTimeout:     Duration(90 * time.Second),
+	DialTimeout:         Duration(30 * time.Second),
+	KeepAlive:           Duration(30 * time.Second),
+	TLSHandshakeTimeout: Duration(10 * time.Second),
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig
+	if c.Timeout == 0 {
+		c.Timeout = d.Timeout
+	}
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = d.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost == 0 {
+		c.MaxIdleConnsPerHost = d.MaxIdleConnsPerHost
+	}
+	if c.IdleConnTimeout == 0 {
+		c.IdleConnTimeout = d.IdleConnTimeout
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = d.DialTimeout
+	}
+	if c.KeepAlive == 0 {
+		c.KeepAlive = d.KeepAlive
+	}
+	if c.TLSHandshakeTimeout == 0 {
+		c.TLSHandshakeTimeout = d.TLSHandshakeTimeout
+	}
+	return c
+}
+
+func (c Config) proxy() (func(*http.Request) (*url.URL, error), error) {
+	switch c.Proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case "none":
+		return nil, nil
+	}
+	u, err := url.Parse(c.Proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q", c.Proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
+func (t *TLSConfig) config() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t == nil {
+		return cfg, nil
+	}
+
+	switch t.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		cfg.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported TLS min_version %q", t.MinVersion)
+	}
+	cfg.ServerName = t.ServerName
+	cfg.InsecureSkipVerify = t.InsecureSkipVerify
+
+	if t.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in CA file")
+		}
+		cfg.RootCAs = pool
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, errors.New("cert_file and key_file must be given together")
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
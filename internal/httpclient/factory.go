@@ -0,0 +1,110 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Factory builds one *http.Client per upstream, so each gets its own
+// connection pool and settings.
+type Factory struct {
+	configs map[string]Config
+
+	mu      sync.Mutex
+	clients map[string]*http.Client
+}
+
+// NewFactory uses configs by upstream name. Upstreams without a config get
+// DefaultConfig.
+func NewFactory(configs map[string]Config) *Factory {
+	if configs == nil {
+		configs = map[string]Config{}
+	}
+	return &Factory{configs: configs, clients: map[string]*http.Client{}}
+}
+
+// LoadFactory reads configs from a JSON object keyed by upstream name.
+func LoadFactory(path string) (*Factory, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading client config: %w", err)
+	}
+	var configs map[string]Config
+	if err := json.Unmarshal(b, &configs); err != nil {
+		return nil, fmt.Errorf("decoding client config: %w", err)
+	}
+	return NewFactory(configs), nil
+}
+
+// Client returns upstream's client, building it on first use.
+func (f *Factory) Client(upstream string) (*http.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if c, ok := f.clients[upstream]; ok {
+		return c, nil
+	}
+	c, err := newClient(f.configs[upstream].withDefaults())
+	if err != nil {
+		return nil, fmt.Errorf("%s client: %w", upstream, err)
+	}
+	f.clients[upstream] = c
+	return c, nil
+}
+
+// Doer returns upstream's client wrapped in middlewares, outermost first.
+func (f *Factory) Doer(upstream string, middlewares ...cat.DoerMiddleware) (cat.Doer, error) {
+	c, err := f.Client(upstream)
+	if err != nil {
+		return nil, err
+	}
+	return cat.Chain(c, middlewares...), nil
+}
+
+func newClient(c Config) (*http.Client, error) {
+	proxy, err := c.proxy()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := c.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   time.Duration(c.DialTimeout),
+			KeepAlive: time.Duration(c.KeepAlive),
+		}).DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: time.Duration(c.TLSHandshakeTimeout),
+		MaxIdleConns:        c.MaxIdleConns,
+		MaxIdleConnsPerHost: c.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     c.MaxConnsPerHost,
+		IdleConnTimeout:     time.Duration(c.IdleConnTimeout),
+		DisableKeepAlives:   c.DisableKeepAlives,
+		ForceAttemptHTTP2:   !c.DisableHTTP2,
+	}
+	if c.DisableHTTP2 {
+		// A non-nil, empty map is how net/http is told not to upgrade.
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return &http.Client{Timeout: time.Duration(c.Timeout), Transport: t}, nil
+}
+
+// WithoutRedirects returns a copy of c that hands redirect responses back
+// rather than following them, so the target is never downloaded and the
+// request's credential headers never reach it.
+func WithoutRedirects(c *http.Client) *http.Client {
+	nc := *c
+	nc.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return &nc
+}
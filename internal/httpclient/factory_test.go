@@ -0,0 +1,107 @@
+package httpclient_test
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"github.com/matthewjamesboyle/catserver/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFactory_Client(t *testing.T) {
+	t.Run("Builds a client per upstream from its config", func(t *testing.T) {
+		f := httpclient.NewFactory(map[string]httpclient.Config{
+			"image": {
+				Timeout:         httpclient.Duration(3 * time.Second),
+				MaxConnsPerHost: 8,
+				DisableHTTP2:    true,
+				Proxy:           "http://proxy.example:3128",
+				TLS:             &httpclient.TLSConfig{MinVersion: "1.3"},
+			},
+		})
+
+		c, err := f.Client("image")
+		require.NoError(t, err)
+		assert.Equal(t, 3*time.Second, c.Timeout)
+		tr := c.Transport.(*http.Transport)
+		assert.Equal(t, 8, tr.MaxConnsPerHost)
+		assert.Equal(t, 20, tr.MaxIdleConnsPerHost, "defaulted")
+		assert.False(t, tr.ForceAttemptHTTP2)
+		assert.NotNil(t, tr.TLSNextProto)
+		assert.Equal(t, uint16(tls.VersionTLS13), tr.TLSClientConfig.MinVersion)
+		p, err := tr.Proxy(httptest.NewRequest(http.MethodGet, "https://cats.example", nil))
+		require.NoError(t, err)
+		assert.Equal(t, "proxy.example:3128", p.Host)
+
+		again, err := f.Client("image")
+		require.NoError(t, err)
+		assert.Same(t, c, again)
+
+		other, err := f.Client("fact")
+		require.NoError(t, err)
+		assert.NotSame(t, c, other)
+		assert.Equal(t, 10*time.Second, other.Timeout)
+		assert.True(t, other.Transport.(*http.Transport).ForceAttemptHTTP2)
+	})
+
+	t.Run("Returns an error given an invalid config", func(t *testing.T) {
+		for _, cfg := range []httpclient.Config{
+			{Proxy: "not a url"},
+			{TLS: &httpclient.TLSConfig{MinVersion: "1.0"}},
+			{TLS: &httpclient.TLSConfig{CertFile: "cert.pem"}},
+		} {
+			_, err := httpclient.NewFactory(map[string]httpclient.Config{"fact": cfg}).Client("fact")
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("Trusts the configured CA", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+		defer srv.Close()
+
+		dir := t.TempDir()
+		ca := filepath.Join(dir, "ca.pem")
+		require.NoError(t, os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600))
+		config := filepath.Join(dir, "clients.json")
+		require.NoError(t, os.WriteFile(config, []byte(`{"fact": {"timeout": "2s", "proxy": "none", "tls": {"ca_file": "`+ca+`"}}}`), 0600))
+
+		f, err := httpclient.LoadFactory(config)
+		require.NoError(t, err)
+		d, err := f.Doer("fact")
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		res, err := d.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+}
+
+func TestWithoutRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("the redirect was followed")
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, target.URL+"/cat.jpg", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	c, err := httpclient.NewFactory(nil).Client("image")
+	require.NoError(t, err)
+	res, err := httpclient.WithoutRedirects(c).Get(srv.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+	assert.Equal(t, target.URL+"/cat.jpg", res.Header.Get("Location"))
+	assert.Nil(t, c.CheckRedirect, "the factory's client is left alone")
+}
@@ -0,0 +1,44 @@
+// Package httpstatus lets middleware see the status code a handler answered
+// with.
+package httpstatus
+
+import "net/http"
+
+// Recorder wraps a ResponseWriter, remembering the status code written
+// through it. It is always an http.Flusher, so streaming handlers behind it
+// still flush; Flush does nothing if the wrapped writer can't.
+type Recorder struct {
+	http.ResponseWriter
+	// Code is the status written, http.StatusOK until then.
+	Code  int
+	wrote bool
+}
+
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, Code: http.StatusOK}
+}
+
+func (r *Recorder) WriteHeader(code int) {
+	if !r.wrote {
+		r.Code = code
+		r.wrote = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *Recorder) Write(b []byte) (int, error) {
+	r.wrote = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *Recorder) Flush() {
+	r.wrote = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer.
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
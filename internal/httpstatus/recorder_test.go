@@ -0,0 +1,42 @@
+package httpstatus_test
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/httpstatus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("Records the first status written", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		rec := httpstatus.NewRecorder(rr)
+
+		rec.WriteHeader(http.StatusTeapot)
+		rec.WriteHeader(http.StatusInternalServerError)
+
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+	})
+
+	t.Run("Records 200 given a body written without a status", func(t *testing.T) {
+		rec := httpstatus.NewRecorder(httptest.NewRecorder())
+
+		_, _ = rec.Write([]byte("some-body"))
+		rec.WriteHeader(http.StatusInternalServerError)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Flushes the wrapped writer", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		var w http.ResponseWriter = httpstatus.NewRecorder(rr)
+
+		f, ok := w.(http.Flusher)
+		assert.True(t, ok)
+		f.Flush()
+		assert.True(t, rr.Flushed)
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	})
+}
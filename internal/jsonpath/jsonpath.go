@@ -0,0 +1,134 @@
+// Package jsonpath evaluates a small subset of JSONPath against decoded
+// JSON: a root $, followed by .field, ["field"] and [index] steps, e.g.
+// $.data[0].fact or $["fact"].
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type step struct {
+	field string
+	index int
+	isIdx bool
+}
+
+// Path is a compiled JSONPath expression.
+type Path struct {
+	expr  string
+	steps []step
+}
+
+// Compile parses expr.
+func Compile(expr string) (Path, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return Path{}, fmt.Errorf("jsonpath %q: must start with $", expr)
+	}
+
+	p := Path{expr: expr}
+	rest := expr[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return Path{}, fmt.Errorf("jsonpath %q: empty field name", expr)
+			}
+			p.steps = append(p.steps, step{field: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return Path{}, fmt.Errorf("jsonpath %q: unclosed [", expr)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				p.steps = append(p.steps, step{field: inner[1 : len(inner)-1]})
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil || n < 0 {
+				return Path{}, fmt.Errorf("jsonpath %q: bad index %q", expr, inner)
+			}
+			p.steps = append(p.steps, step{index: n, isIdx: true})
+		default:
+			return Path{}, fmt.Errorf("jsonpath %q: unexpected %q", expr, rest[0])
+		}
+	}
+	return p, nil
+}
+
+// MustCompile is Compile for expressions known to be valid.
+func MustCompile(expr string) Path {
+	p, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p Path) String() string {
+	return p.expr
+}
+
+// Get walks v, as decoded by encoding/json into an interface{}, and returns
+// the value the path points at.
+func (p Path) Get(v interface{}) (interface{}, error) {
+	for _, s := range p.steps {
+		if s.isIdx {
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("jsonpath %s: [%d] of a non-array", p.expr, s.index)
+			}
+			if s.index >= len(arr) {
+				return nil, fmt.Errorf("jsonpath %s: index %d out of range", p.expr, s.index)
+			}
+			v = arr[s.index]
+			continue
+		}
+
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("jsonpath %s: field %q of a non-object", p.expr, s.field)
+		}
+		v, ok = obj[s.field]
+		if !ok {
+			return nil, fmt.Errorf("jsonpath %s: no field %q", p.expr, s.field)
+		}
+	}
+	return v, nil
+}
+
+// GetString returns the string the path points at in v.
+func (p Path) GetString(v interface{}) (string, error) {
+	x, err := p.Get(v)
+	if err != nil {
+		return "", err
+	}
+	s, ok := x.(string)
+	if !ok {
+		return "", fmt.Errorf("jsonpath %s: not a string", p.expr)
+	}
+	return s, nil
+}
+
+// UnmarshalText lets a Path be read straight from JSON config.
+func (p *Path) UnmarshalText(b []byte) error {
+	c, err := Compile(string(b))
+	if err != nil {
+		return err
+	}
+	*p = c
+	return nil
+}
+
+func (p Path) MarshalText() ([]byte, error) {
+	return []byte(p.expr), nil
+}
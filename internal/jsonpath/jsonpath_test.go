@@ -0,0 +1,56 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"github.com/matthewjamesboyle/catserver/internal/jsonpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPath_GetString(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"fact": "top",
+		"data": ["first", "second"],
+		"nested": {"items": [{"text": "deep"}], "odd key": "quoted"},
+		"count": 3
+	}`), &doc))
+
+	for expr, want := range map[string]string{
+		`$.fact`:                       "top",
+		`$.data[1]`:                    "second",
+		`$.nested.items[0].text`:       "deep",
+		`$["nested"]["odd key"]`:       "quoted",
+		`$['nested'].items[0]['text']`: "deep",
+	} {
+		got, err := jsonpath.MustCompile(expr).GetString(doc)
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, got, expr)
+	}
+
+	for expr, msg := range map[string]string{
+		`$.missing`: `jsonpath $.missing: no field "missing"`,
+		`$.data[5]`: `jsonpath $.data[5]: index 5 out of range`,
+		`$.fact[0]`: `jsonpath $.fact[0]: [0] of a non-array`,
+		`$.data.x`:  `jsonpath $.data.x: field "x" of a non-object`,
+		`$.count`:   `jsonpath $.count: not a string`,
+	} {
+		_, err := jsonpath.MustCompile(expr).GetString(doc)
+		assert.EqualError(t, err, msg, expr)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	for _, expr := range []string{`fact`, `$.`, `$[0`, `$[x]`, `$[-1]`, `$fact`} {
+		_, err := jsonpath.Compile(expr)
+		assert.Error(t, err, expr)
+	}
+
+	var cfg struct {
+		Path jsonpath.Path `json:"path"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"path": "$.data[0]"}`), &cfg))
+	assert.Equal(t, "$.data[0]", cfg.Path.String())
+	assert.Error(t, json.Unmarshal([]byte(`{"path": "data"}`), &cfg))
+}
@@ -0,0 +1,148 @@
+package loadshed
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// Defaults for a Config.
+const (
+	DefaultInitialLimit = 20
+	DefaultMinLimit     = 5
+	DefaultMaxLimit     = 500
+	DefaultSmoothing    = 0.2
+	DefaultWindow       = time.Second
+)
+
+// baselineWeight is how much each window moves the baseline latency. It is
+// small so the baseline tracks what's normal over the last minute or so,
+// rather than what's happening now.
+const baselineWeight = 0.05
+
+// Config tunes a Limiter. Zero values take the defaults.
+type Config struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// Smoothing is how far the limit moves towards its new value after
+	// each window, from 0 to 1.
+	Smoothing float64
+	// Window is how long latencies are averaged over before the limit is
+	// adjusted.
+	Window time.Duration
+}
+
+// Limiter caps how many requests are served at once. Once per window the
+// cap follows the gradient between the baseline latency and the window's
+// average: when requests slow down the limit shrinks, and while they are as
+// fast as usual it grows by about its square root, which leaves room to
+// queue a little.
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	baseline float64
+
+	windowStart time.Time
+	sum         float64
+	samples     int
+	maxInFlight int
+}
+
+func NewLimiter(cfg Config) (*Limiter, error) {
+	if cfg.MinLimit == 0 {
+		cfg.MinLimit = DefaultMinLimit
+	}
+	if cfg.MaxLimit == 0 {
+		cfg.MaxLimit = DefaultMaxLimit
+	}
+	if cfg.InitialLimit == 0 {
+		cfg.InitialLimit = max(cfg.MinLimit, min(DefaultInitialLimit, cfg.MaxLimit))
+	}
+	if cfg.Smoothing == 0 {
+		cfg.Smoothing = DefaultSmoothing
+	}
+	if cfg.Window == 0 {
+		cfg.Window = DefaultWindow
+	}
+	if cfg.MinLimit < 1 || cfg.MinLimit > cfg.MaxLimit {
+		return nil, errors.New("limits must satisfy 1 <= MinLimit <= MaxLimit")
+	}
+	if cfg.InitialLimit < cfg.MinLimit || cfg.InitialLimit > cfg.MaxLimit {
+		return nil, errors.New("InitialLimit must be between MinLimit and MaxLimit")
+	}
+	if cfg.Smoothing < 0 || cfg.Smoothing > 1 {
+		return nil, errors.New("Smoothing must be between 0 and 1")
+	}
+	if cfg.Window < 0 {
+		return nil, errors.New("Window must not be negative")
+	}
+	return &Limiter{cfg: cfg, now: time.Now, limit: float64(cfg.InitialLimit)}, nil
+}
+
+// Acquire admits a request if fewer than Limit are in flight. The returned
+// func must be called when the request is done, to record its latency.
+func (l *Limiter) Acquire() (func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= int(l.limit) {
+		return nil, false
+	}
+	l.inFlight++
+	if l.inFlight > l.maxInFlight {
+		l.maxInFlight = l.inFlight
+	}
+	start := l.now()
+	if l.windowStart.IsZero() {
+		l.windowStart = start
+	}
+	return func() { l.release(start) }, true
+}
+
+// Limit is the current number of requests allowed in flight.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+func (l *Limiter) release(start time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	now := l.now()
+	l.sum += now.Sub(start).Seconds()
+	l.samples++
+	if now.Sub(l.windowStart) < l.cfg.Window {
+		return
+	}
+
+	rtt := l.sum / float64(l.samples)
+	maxInFlight := l.maxInFlight
+	l.windowStart, l.sum, l.samples, l.maxInFlight = now, 0, 0, l.inFlight
+	if rtt <= 0 {
+		return
+	}
+	if l.baseline == 0 {
+		l.baseline = rtt
+	} else {
+		l.baseline += baselineWeight * (rtt - l.baseline)
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.baseline/rtt))
+	next := l.limit*gradient + math.Sqrt(l.limit)
+	// Don't grow a limit that isn't being used, or a burst after a quiet
+	// spell could be let straight through.
+	if maxInFlight < int(l.limit)/2 {
+		next = math.Min(next, l.limit)
+	}
+	next = l.limit + l.cfg.Smoothing*(next-l.limit)
+	l.limit = math.Max(float64(l.cfg.MinLimit), math.Min(float64(l.cfg.MaxLimit), next))
+}
@@ -0,0 +1,102 @@
+package loadshed
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter(t *testing.T, cfg Config) (*Limiter, *fakeClock) {
+	t.Helper()
+
+	l, err := NewLimiter(cfg)
+	require.NoError(t, err)
+	c := &fakeClock{t: time.Unix(0, 0)}
+	l.now = c.now
+	return l, c
+}
+
+// serve runs n requests at once, each taking latency.
+func serve(t *testing.T, l *Limiter, c *fakeClock, n int, latency time.Duration) {
+	t.Helper()
+
+	var done []func()
+	for i := 0; i < n; i++ {
+		d, ok := l.Acquire()
+		require.True(t, ok)
+		done = append(done, d)
+	}
+	c.t = c.t.Add(latency)
+	for _, d := range done {
+		d()
+	}
+}
+
+func TestNewLimiter(t *testing.T) {
+	for _, cfg := range []Config{
+		{MinLimit: 10, MaxLimit: 5},
+		{InitialLimit: 1, MinLimit: 2},
+		{Smoothing: 2},
+		{Window: -time.Second},
+	} {
+		l, err := NewLimiter(cfg)
+		assert.Nil(t, l)
+		assert.Error(t, err)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	t.Run("Rejects requests over the limit", func(t *testing.T) {
+		l, _ := newTestLimiter(t, Config{InitialLimit: 2, MinLimit: 1})
+
+		_, ok := l.Acquire()
+		require.True(t, ok)
+		done, ok := l.Acquire()
+		require.True(t, ok)
+		_, ok = l.Acquire()
+		assert.False(t, ok)
+
+		done()
+		_, ok = l.Acquire()
+		assert.True(t, ok)
+	})
+
+	t.Run("Grows while latency holds steady under load", func(t *testing.T) {
+		l, c := newTestLimiter(t, Config{InitialLimit: 10, Window: 100 * time.Millisecond})
+
+		for i := 0; i < 20; i++ {
+			serve(t, l, c, l.Limit(), 100*time.Millisecond)
+		}
+		assert.Greater(t, l.Limit(), 20)
+	})
+
+	t.Run("Doesn't grow when the limit isn't used", func(t *testing.T) {
+		l, c := newTestLimiter(t, Config{InitialLimit: 10, Window: 100 * time.Millisecond})
+
+		for i := 0; i < 20; i++ {
+			serve(t, l, c, 1, 100*time.Millisecond)
+		}
+		assert.Equal(t, 10, l.Limit())
+	})
+
+	t.Run("Shrinks towards the minimum as latency rises", func(t *testing.T) {
+		l, c := newTestLimiter(t, Config{InitialLimit: 100, MinLimit: 5, Window: 100 * time.Millisecond})
+
+		for i := 0; i < 5; i++ {
+			serve(t, l, c, l.Limit(), 100*time.Millisecond)
+		}
+		start := l.Limit()
+		for i := 0; i < 20; i++ {
+			serve(t, l, c, l.Limit(), time.Second)
+		}
+		assert.Less(t, l.Limit(), start/2)
+		assert.GreaterOrEqual(t, l.Limit(), 5)
+	})
+}
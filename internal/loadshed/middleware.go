@@ -0,0 +1,64 @@
+package loadshed
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryAfter is what shed requests are told to wait.
+const DefaultRetryAfter = time.Second
+
+// Middleware sheds requests the Limiter won't admit.
+type Middleware struct {
+	l          *Limiter
+	retryAfter time.Duration
+	exempt     map[string]bool
+}
+
+func NewMiddleware(l *Limiter, retryAfter time.Duration) (*Middleware, error) {
+	if l == nil {
+		return nil, cat.ErrNilParam{Parameter: "l"}
+	}
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+	return &Middleware{l: l, retryAfter: retryAfter, exempt: make(map[string]bool)}, nil
+}
+
+// Exempt lets the route with the given path template through without
+// counting against the limit, e.g. for health checks.
+func (m *Middleware) Exempt(tpl string) {
+	m.exempt[tpl] = true
+}
+
+func (m *Middleware) exempted(req *http.Request) bool {
+	if r := mux.CurrentRoute(req); r != nil {
+		if tpl, err := r.GetPathTemplate(); err == nil {
+			return m.exempt[tpl]
+		}
+	}
+	return false
+}
+
+// Handler is installed with mux.Router.Use.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if m.exempted(req) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		done, ok := m.l.Acquire()
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(m.retryAfter.Seconds()))))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		defer done()
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,55 @@
+package loadshed_test
+
+import (
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/loadshed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewMiddleware(t *testing.T) {
+	m, err := loadshed.NewMiddleware(nil, time.Second)
+
+	assert.Nil(t, m)
+	var e cat.ErrNilParam
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "l", e.Parameter)
+}
+
+func TestMiddleware_Handler(t *testing.T) {
+	l, err := loadshed.NewLimiter(loadshed.Config{InitialLimit: 1, MinLimit: 1})
+	require.NoError(t, err)
+	m, err := loadshed.NewMiddleware(l, 2*time.Second)
+	require.NoError(t, err)
+	m.Exempt("/healthz")
+
+	r := mux.NewRouter()
+	r.Use(m.Handler)
+	ok := func(w http.ResponseWriter, _ *http.Request) {}
+	r.HandleFunc("/", ok)
+	r.HandleFunc("/healthz", ok)
+
+	// Hold the only slot, as a slow request would.
+	done, admitted := l.Acquire()
+	require.True(t, admitted)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	done()
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats understood by New.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+type ctxKey struct{}
+
+// New returns a logger writing to w at or above level ("debug", "info",
+// "warn" or "error") in the given format.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToUpper(level))); err != nil {
+		return nil, fmt.Errorf("parsing log level: %w", err)
+	}
+
+	opts := &slog.HandlerOptions{Level: l}
+	switch format {
+	case "", FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+}
+
+// WithLogger returns a copy of ctx carrying l.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the request-scoped logger stored in ctx, or
+// slog.Default if there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
@@ -0,0 +1,56 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Writes JSON at or above the given level", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := logging.New(&buf, "warn", logging.FormatJSON)
+		require.NoError(t, err)
+
+		l.Info("some-info")
+		l.Warn("some-warning", "key", "value")
+
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "WARN", line["level"])
+		assert.Equal(t, "some-warning", line["msg"])
+		assert.Equal(t, "value", line["key"])
+	})
+
+	t.Run("Returns an error given an unknown level", func(t *testing.T) {
+		l, err := logging.New(&bytes.Buffer{}, "loud", logging.FormatJSON)
+
+		assert.Nil(t, l)
+		assert.Error(t, err)
+	})
+
+	t.Run("Returns an error given an unknown format", func(t *testing.T) {
+		l, err := logging.New(&bytes.Buffer{}, "info", "xml")
+
+		assert.Nil(t, l)
+		assert.Error(t, err)
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	t.Run("Returns the default logger given an empty context", func(t *testing.T) {
+		assert.Equal(t, slog.Default(), logging.FromContext(context.Background()))
+	})
+
+	t.Run("Returns the logger stored in the context", func(t *testing.T) {
+		l := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+		ctx := logging.WithLogger(context.Background(), l)
+
+		assert.Equal(t, l, logging.FromContext(ctx))
+	})
+}
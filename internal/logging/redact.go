@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Redacted replaces anything a redacting handler hides.
+const Redacted = "[REDACTED]"
+
+// Redactor hides secrets in log output. Redact returns s with any secrets
+// replaced; Sensitive reports whether an attribute named key should be
+// hidden whatever its value.
+type Redactor interface {
+	Redact(s string) string
+	Sensitive(key string) bool
+}
+
+type redactingHandler struct {
+	next slog.Handler
+	r    Redactor
+}
+
+// NewRedactingHandler wraps next so that secrets known to r never reach it,
+// in messages or attribute values.
+func NewRedactingHandler(next slog.Handler, r Redactor) slog.Handler {
+	return &redactingHandler{next: next, r: r}
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, h.r.Redact(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.attr(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted), r: h.r}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name), r: h.r}
+}
+
+func (h *redactingHandler) attr(a slog.Attr) slog.Attr {
+	if h.r.Sensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.r.Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, g := range group {
+			redacted[i] = h.attr(g)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		// Errors and anything else with a String form are logged as text,
+		// so redact that.
+		switch x := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, h.r.Redact(x.Error()))
+		case interface{ String() string }:
+			return slog.String(a.Key, h.r.Redact(x.String()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,36 @@
+package logging_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type redactor struct{}
+
+func (redactor) Redact(s string) string    { return strings.ReplaceAll(s, "hunter2", logging.Redacted) }
+func (redactor) Sensitive(key string) bool { return key == "authorization" }
+
+func TestNewRedactingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(logging.NewRedactingHandler(slog.NewTextHandler(&buf, nil), redactor{}))
+
+	l.With("token", "hunter2").WithGroup("req").Error("login with hunter2 failed",
+		"error", errors.New("bad password hunter2"),
+		"authorization", "Basic abc",
+		slog.Group("upstream", "url", "http://x/?p=hunter2"),
+	)
+
+	out := buf.String()
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "Basic abc")
+	assert.Contains(t, out, `msg="login with [REDACTED] failed"`)
+	assert.Contains(t, out, `token=[REDACTED]`)
+	assert.Contains(t, out, `req.error="bad password [REDACTED]"`)
+	assert.Contains(t, out, `req.authorization=[REDACTED]`)
+	assert.Contains(t, out, `req.upstream.url="http://x/?p=[REDACTED]"`)
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+)
+
+// RequestIDHeader is read from incoming requests and echoed on responses.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength stops callers stuffing arbitrarily large values into
+// every log line.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID stored by Middleware, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Middleware propagates the caller's X-Request-ID, or assigns a new one, and
+// stores it along with a logger carrying it on the request context.
+func Middleware(base *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			id := req.Header.Get(RequestIDHeader)
+			if id == "" || len(id) > maxRequestIDLength {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx := context.WithValue(req.Context(), requestIDKey{}, id)
+			ctx = WithLogger(ctx, base.With(slog.String("request_id", id)))
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,63 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	newHandler := func(buf *bytes.Buffer, gotID *string) http.Handler {
+		base := slog.New(slog.NewJSONHandler(buf, nil))
+		return logging.Middleware(base)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			*gotID = logging.RequestIDFromContext(req.Context())
+			logging.FromContext(req.Context()).Info("handled")
+		}))
+	}
+
+	t.Run("Propagates an incoming request ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		var id string
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(logging.RequestIDHeader, "some-id")
+		rr := httptest.NewRecorder()
+
+		newHandler(&buf, &id).ServeHTTP(rr, req)
+
+		assert.Equal(t, "some-id", id)
+		assert.Equal(t, "some-id", rr.Header().Get(logging.RequestIDHeader))
+
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "some-id", line["request_id"])
+	})
+
+	t.Run("Assigns a request ID when none is sent", func(t *testing.T) {
+		var buf bytes.Buffer
+		var id string
+		rr := httptest.NewRecorder()
+
+		newHandler(&buf, &id).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Len(t, id, 32)
+		assert.Equal(t, id, rr.Header().Get(logging.RequestIDHeader))
+	})
+
+	t.Run("Replaces an oversized request ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		var id string
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(logging.RequestIDHeader, strings.Repeat("a", 1000))
+
+		newHandler(&buf, &id).ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Len(t, id, 32)
+	})
+}
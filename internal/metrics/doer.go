@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type instrumentedDoer struct {
+	m        *Metrics
+	upstream string
+	next     cat.Doer
+}
+
+// Doer wraps next so that every call is counted and timed under the given
+// upstream label, e.g. "fact" or "image".
+func (m *Metrics) Doer(upstream string, next cat.Doer) cat.Doer {
+	return &instrumentedDoer{m: m, upstream: upstream, next: next}
+}
+
+func (d *instrumentedDoer) Do(req *http.Request) (*http.Response, error) {
+	inFlight := d.m.upstreamInFlight.WithLabelValues(d.upstream)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	start := time.Now()
+	res, err := d.next.Do(req)
+	outcome := outcome(res, err)
+
+	d.m.upstreamRequests.WithLabelValues(d.upstream, outcome).Inc()
+	d.m.upstreamDuration.WithLabelValues(d.upstream, outcome).Observe(time.Since(start).Seconds())
+
+	return res, err
+}
+
+// outcome is "error" for transport failures and the status code otherwise.
+func outcome(res *http.Response, err error) string {
+	if err != nil || res == nil {
+		return "error"
+	}
+	return strconv.Itoa(res.StatusCode)
+}
@@ -0,0 +1,58 @@
+package metrics_test
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/metrics"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMetrics_Doer(t *testing.T) {
+	t.Run("Counts successful and failed calls by upstream", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		reg := prometheus.NewRegistry()
+		m, err := metrics.NewMetrics(reg)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, "http://some-url", nil)
+		require.NoError(t, err)
+
+		md := mockcat.NewMockDoer(ctrl)
+		testErr := errors.New("some-error")
+		gomock.InOrder(
+			md.EXPECT().Do(req).Return(&http.Response{StatusCode: http.StatusOK}, nil),
+			md.EXPECT().Do(req).Return(nil, testErr),
+		)
+
+		d := m.Doer("fact", md)
+
+		_, err = d.Do(req)
+		assert.NoError(t, err)
+		_, err = d.Do(req)
+		assert.True(t, errors.Is(err, testErr))
+
+		expected := `
+# HELP catserver_upstream_requests_total Calls made to upstream APIs, by upstream and outcome.
+# TYPE catserver_upstream_requests_total counter
+catserver_upstream_requests_total{outcome="200",upstream="fact"} 1
+catserver_upstream_requests_total{outcome="error",upstream="fact"} 1
+`
+		assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "catserver_upstream_requests_total"))
+
+		expected = `
+# HELP catserver_upstream_requests_in_flight Upstream calls currently in progress, by upstream.
+# TYPE catserver_upstream_requests_in_flight gauge
+catserver_upstream_requests_in_flight{upstream="fact"} 0
+`
+		assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "catserver_upstream_requests_in_flight"))
+	})
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const namespace = "catserver"
+
+// Metrics holds the collectors for the HTTP handlers, the upstream Doers and
+// any caches in front of them.
+type Metrics struct {
+	gatherer prometheus.Gatherer
+
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	requestsInFlight prometheus.Gauge
+
+	upstreamRequests *prometheus.CounterVec
+	upstreamDuration *prometheus.HistogramVec
+	upstreamInFlight *prometheus.GaugeVec
+	bulkheadQueued   *prometheus.GaugeVec
+	bulkheadRejected *prometheus.CounterVec
+	cacheLookups     *prometheus.CounterVec
+}
+
+// NewMetrics creates the collectors and registers them with reg. The
+// registry is also used to serve /metrics.
+func NewMetrics(reg *prometheus.Registry) (*Metrics, error) {
+	if reg == nil {
+		return nil, cat.ErrNilParam{Parameter: "reg"}
+	}
+
+	m := &Metrics{
+		gatherer: reg,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests handled, by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency, by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "code"}),
+		requestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "HTTP requests currently being served.",
+		}),
+		upstreamRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "requests_total",
+			Help:      "Calls made to upstream APIs, by upstream and outcome.",
+		}, []string{"upstream", "outcome"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "request_duration_seconds",
+			Help:      "Upstream call latency, by upstream and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"upstream", "outcome"}),
+		upstreamInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "requests_in_flight",
+			Help:      "Upstream calls currently in progress, by upstream.",
+		}, []string{"upstream"}),
+		bulkheadQueued: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "bulkhead_queued",
+			Help:      "Upstream calls waiting for a bulkhead slot, by upstream.",
+		}, []string{"upstream"}),
+		bulkheadRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "upstream",
+			Name:      "bulkhead_rejections_total",
+			Help:      "Upstream calls turned away because the bulkhead was full, by upstream.",
+		}, []string{"upstream"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "lookups_total",
+			Help:      "Cache lookups, by cache and result (hit or miss).",
+		}, []string{"cache", "result"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		m.requests, m.requestDuration, m.requestsInFlight,
+		m.upstreamRequests, m.upstreamDuration, m.upstreamInFlight,
+		m.bulkheadQueued, m.bulkheadRejected,
+		m.cacheLookups,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{})
+}
+
+// CacheLookup records a hit or miss for the named cache. The hit ratio is
+// hits / (hits + misses) of catserver_cache_lookups_total.
+func (m *Metrics) CacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// BulkheadQueued adds delta to the number of calls to upstream waiting for a
+// bulkhead slot.
+func (m *Metrics) BulkheadQueued(upstream string, delta int) {
+	m.bulkheadQueued.WithLabelValues(upstream).Add(float64(delta))
+}
+
+// BulkheadRejected records a call to upstream turned away by its bulkhead.
+func (m *Metrics) BulkheadRejected(upstream string) {
+	m.bulkheadRejected.WithLabelValues(upstream).Inc()
+}
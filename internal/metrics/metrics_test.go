@@ -0,0 +1,66 @@
+package metrics_test
+
+import (
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewMetrics(t *testing.T) {
+	t.Run("Returns Metrics and no error", func(t *testing.T) {
+		m, err := metrics.NewMetrics(prometheus.NewRegistry())
+
+		assert.NotNil(t, m)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Returns an error given a nil registry", func(t *testing.T) {
+		m, err := metrics.NewMetrics(nil)
+
+		assert.Nil(t, m)
+		var e cat.ErrNilParam
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, "reg", e.Parameter)
+	})
+
+	t.Run("Returns an error given the collectors are already registered", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		_, err := metrics.NewMetrics(reg)
+		require.NoError(t, err)
+
+		_, err = metrics.NewMetrics(reg)
+		assert.Error(t, err)
+	})
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m, err := metrics.NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	m.CacheLookup("image", true)
+	m.CacheLookup("image", false)
+	m.CacheLookup("image", false)
+	m.BulkheadQueued("image", 1)
+	m.BulkheadQueued("image", 1)
+	m.BulkheadQueued("image", -1)
+	m.BulkheadRejected("image")
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	b, err := ioutil.ReadAll(rr.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, string(b), `catserver_cache_lookups_total{cache="image",result="hit"} 1`)
+	assert.Contains(t, string(b), `catserver_cache_lookups_total{cache="image",result="miss"} 2`)
+	assert.Contains(t, string(b), `catserver_upstream_bulkhead_queued{upstream="image"} 1`)
+	assert.Contains(t, string(b), `catserver_upstream_bulkhead_rejections_total{upstream="image"} 1`)
+}
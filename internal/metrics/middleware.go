@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/httpstatus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware records request counts and latency for every route of a
+// mux.Router. It is meant to be installed with Router.Use so the matched
+// route template is available.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		m.requestsInFlight.Inc()
+		defer m.requestsInFlight.Dec()
+
+		route := "unknown"
+		if r := mux.CurrentRoute(req); r != nil {
+			if tpl, err := r.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		rec := httpstatus.NewRecorder(w)
+		start := time.Now()
+		next.ServeHTTP(rec, req)
+
+		code := strconv.Itoa(rec.Code)
+		m.requests.WithLabelValues(route, req.Method, code).Inc()
+		m.requestDuration.WithLabelValues(route, req.Method, code).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,59 @@
+package metrics_test
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics_Middleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := metrics.NewMetrics(reg)
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	r.Use(m.Middleware)
+	r.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	r.HandleFunc("/c/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/c/abc", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/c/def", nil))
+
+	expected := `
+# HELP catserver_http_requests_total HTTP requests handled, by route, method and status code.
+# TYPE catserver_http_requests_total counter
+catserver_http_requests_total{code="200",method="GET",route="/c/{id}"} 2
+catserver_http_requests_total{code="500",method="GET",route="/"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "catserver_http_requests_total"))
+}
+
+func TestMetrics_Middleware_Flush(t *testing.T) {
+	m, err := metrics.NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	r.Use(m.Middleware)
+	r.HandleFunc("/stream", func(w http.ResponseWriter, _ *http.Request) {
+		f, ok := w.(http.Flusher)
+		require.True(t, ok)
+		f.Flush()
+	})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+	assert.True(t, rr.Flushed)
+}
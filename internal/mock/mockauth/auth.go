@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/matthewjamesboyle/catserver/internal/auth (interfaces: KeyStore)
+
+// Package mockauth is a generated GoMock package.
+package mockauth
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	auth "github.com/matthewjamesboyle/catserver/internal/auth"
+	reflect "reflect"
+)
+
+// MockKeyStore is a mock of KeyStore interface
+type MockKeyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyStoreMockRecorder
+}
+
+// MockKeyStoreMockRecorder is the mock recorder for MockKeyStore
+type MockKeyStoreMockRecorder struct {
+	mock *MockKeyStore
+}
+
+// NewMockKeyStore creates a new mock instance
+func NewMockKeyStore(ctrl *gomock.Controller) *MockKeyStore {
+	mock := &MockKeyStore{ctrl: ctrl}
+	mock.recorder = &MockKeyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockKeyStore) EXPECT() *MockKeyStoreMockRecorder {
+	return m.recorder
+}
+
+// Lookup mocks base method
+func (m *MockKeyStore) Lookup(arg0 string) (auth.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", arg0)
+	ret0, _ := ret[0].(auth.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lookup indicates an expected call of Lookup
+func (mr *MockKeyStoreMockRecorder) Lookup(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockKeyStore)(nil).Lookup), arg0)
+}
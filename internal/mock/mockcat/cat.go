@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/matthewjamesboyle/catserver/internal/cat (interfaces: FactGetter,ImageGetter,Doer,Servicer)
+// Source: github.com/matthewjamesboyle/catserver/internal/cat (interfaces: FactGetter,ImageGetter,Doer,Servicer,SessionHistory,Translator,BreedLister)
 
 // Package mockcat is a generated GoMock package.
 package mockcat
@@ -74,10 +74,10 @@ func (m *MockImageGetter) EXPECT() *MockImageGetterMockRecorder {
 }
 
 // GetImage mocks base method
-func (m *MockImageGetter) GetImage(arg0 context.Context) (cat.ImageURL, error) {
+func (m *MockImageGetter) GetImage(arg0 context.Context) (cat.Image, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetImage", arg0)
-	ret0, _ := ret[0].(cat.ImageURL)
+	ret0, _ := ret[0].(cat.Image)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -163,3 +163,116 @@ func (mr *MockServicerMockRecorder) GetImageAndFact(arg0 interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageAndFact", reflect.TypeOf((*MockServicer)(nil).GetImageAndFact), arg0)
 }
+
+// MockSessionHistory is a mock of SessionHistory interface
+type MockSessionHistory struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionHistoryMockRecorder
+}
+
+// MockSessionHistoryMockRecorder is the mock recorder for MockSessionHistory
+type MockSessionHistoryMockRecorder struct {
+	mock *MockSessionHistory
+}
+
+// NewMockSessionHistory creates a new mock instance
+func NewMockSessionHistory(ctrl *gomock.Controller) *MockSessionHistory {
+	mock := &MockSessionHistory{ctrl: ctrl}
+	mock.recorder = &MockSessionHistoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSessionHistory) EXPECT() *MockSessionHistoryMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method
+func (m *MockSessionHistory) Claim(arg0, arg1 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Claim indicates an expected call of Claim
+func (mr *MockSessionHistoryMockRecorder) Claim(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockSessionHistory)(nil).Claim), arg0, arg1)
+}
+
+// MockTranslator is a mock of Translator interface
+type MockTranslator struct {
+	ctrl     *gomock.Controller
+	recorder *MockTranslatorMockRecorder
+}
+
+// MockTranslatorMockRecorder is the mock recorder for MockTranslator
+type MockTranslatorMockRecorder struct {
+	mock *MockTranslator
+}
+
+// NewMockTranslator creates a new mock instance
+func NewMockTranslator(ctrl *gomock.Controller) *MockTranslator {
+	mock := &MockTranslator{ctrl: ctrl}
+	mock.recorder = &MockTranslatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTranslator) EXPECT() *MockTranslatorMockRecorder {
+	return m.recorder
+}
+
+// Translate mocks base method
+func (m *MockTranslator) Translate(arg0 context.Context, arg1 cat.Fact, arg2 string) (cat.Fact, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Translate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(cat.Fact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Translate indicates an expected call of Translate
+func (mr *MockTranslatorMockRecorder) Translate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Translate", reflect.TypeOf((*MockTranslator)(nil).Translate), arg0, arg1, arg2)
+}
+
+// MockBreedLister is a mock of BreedLister interface
+type MockBreedLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockBreedListerMockRecorder
+}
+
+// MockBreedListerMockRecorder is the mock recorder for MockBreedLister
+type MockBreedListerMockRecorder struct {
+	mock *MockBreedLister
+}
+
+// NewMockBreedLister creates a new mock instance
+func NewMockBreedLister(ctrl *gomock.Controller) *MockBreedLister {
+	mock := &MockBreedLister{ctrl: ctrl}
+	mock.recorder = &MockBreedListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBreedLister) EXPECT() *MockBreedListerMockRecorder {
+	return m.recorder
+}
+
+// ListBreeds mocks base method
+func (m *MockBreedLister) ListBreeds(arg0 context.Context) ([]cat.Breed, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBreeds", arg0)
+	ret0, _ := ret[0].([]cat.Breed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBreeds indicates an expected call of ListBreeds
+func (mr *MockBreedListerMockRecorder) ListBreeds(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBreeds", reflect.TypeOf((*MockBreedLister)(nil).ListBreeds), arg0)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/matthewjamesboyle/catserver/internal/favorite (interfaces: FavoriteStore)
+
+// Package mockfavorite is a generated GoMock package.
+package mockfavorite
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	cat "github.com/matthewjamesboyle/catserver/internal/cat"
+	favorite "github.com/matthewjamesboyle/catserver/internal/favorite"
+	reflect "reflect"
+)
+
+// MockFavoriteStore is a mock of FavoriteStore interface
+type MockFavoriteStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockFavoriteStoreMockRecorder
+}
+
+// MockFavoriteStoreMockRecorder is the mock recorder for MockFavoriteStore
+type MockFavoriteStoreMockRecorder struct {
+	mock *MockFavoriteStore
+}
+
+// NewMockFavoriteStore creates a new mock instance
+func NewMockFavoriteStore(ctrl *gomock.Controller) *MockFavoriteStore {
+	mock := &MockFavoriteStore{ctrl: ctrl}
+	mock.recorder = &MockFavoriteStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFavoriteStore) EXPECT() *MockFavoriteStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method
+func (m *MockFavoriteStore) Delete(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockFavoriteStoreMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFavoriteStore)(nil).Delete), arg0, arg1, arg2)
+}
+
+// List mocks base method
+func (m *MockFavoriteStore) List(arg0 context.Context, arg1, arg2 string, arg3 int) ([]favorite.Favorite, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]favorite.Favorite)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List
+func (mr *MockFavoriteStoreMockRecorder) List(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFavoriteStore)(nil).List), arg0, arg1, arg2, arg3)
+}
+
+// Save mocks base method
+func (m *MockFavoriteStore) Save(arg0 context.Context, arg1 string, arg2 cat.CatResult) (favorite.Favorite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", arg0, arg1, arg2)
+	ret0, _ := ret[0].(favorite.Favorite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockFavoriteStoreMockRecorder) Save(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockFavoriteStore)(nil).Save), arg0, arg1, arg2)
+}
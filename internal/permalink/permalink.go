@@ -0,0 +1,87 @@
+package permalink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"strings"
+)
+
+var ErrNotFound = errors.New("permalink not found")
+
+// idLength is in base32 characters, i.e. 50 bits of the hash.
+const idLength = 10
+
+type Store interface {
+	Put(ctx context.Context, c cat.CatResult) error
+	Get(ctx context.Context, id string) (cat.CatResult, error)
+}
+
+var encoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// ID derives a short ID from the fact and image, so the same result always
+// gets the same link.
+func ID(c cat.CatResult) string {
+	h := sha256.Sum256([]byte(string(c.ImageURL) + "\x00" + string(c.Fact)))
+	return encoding.EncodeToString(h[:])[:idLength]
+}
+
+// Servicer stores every result from next and sets its ID. Results that
+// can't be stored are served without one.
+type Servicer struct {
+	next       cat.Servicer
+	store      Store
+	translator cat.ResultTranslator
+}
+
+type ServicerOption func(s *Servicer)
+
+// WithTranslator translates results for the caller after they are stored.
+// Results are stored untranslated, as anyone may follow the link.
+func WithTranslator(t cat.ResultTranslator) ServicerOption {
+	return func(s *Servicer) {
+		s.translator = t
+	}
+}
+
+func NewServicer(next cat.Servicer, store Store, opts ...ServicerOption) (*Servicer, error) {
+	if next == nil {
+		return nil, cat.ErrNilParam{Parameter: "Servicer"}
+	}
+	if store == nil {
+		return nil, cat.ErrNilParam{Parameter: "Store"}
+	}
+	s := &Servicer{next: next, store: store}
+	for _, o := range opts {
+		o(s)
+	}
+	return s, nil
+}
+
+func (s *Servicer) GetImageAndFact(ctx context.Context) (cat.CatResult, error) {
+	c, err := s.next.GetImageAndFact(cat.WithLanguages(ctx))
+	if err != nil {
+		return cat.CatResult{}, err
+	}
+	c.ID = ID(c)
+	if err := s.store.Put(ctx, c); err != nil {
+		// The result is still worth serving, just not as a link.
+		logging.FromContext(ctx).Error("storing permalink", "error", err)
+		c.ID = ""
+	}
+	if s.translator != nil {
+		c = s.translator.TranslateResult(ctx, c)
+	}
+	return c, nil
+}
+
+// ValidID reports whether id could have been returned by ID.
+func ValidID(id string) bool {
+	if len(id) != idLength {
+		return false
+	}
+	return strings.Trim(id, "abcdefghijklmnopqrstuvwxyz234567") == ""
+}
@@ -0,0 +1,142 @@
+package permalink_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/matthewjamesboyle/catserver/internal/permalink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	a := cat.CatResult{ImageURL: "http://someurl", Fact: "some-fact"}
+	b := cat.CatResult{ImageURL: "http://someurl", Fact: "some-other-fact"}
+
+	assert.Equal(t, permalink.ID(a), permalink.ID(a))
+	assert.NotEqual(t, permalink.ID(a), permalink.ID(b))
+	assert.True(t, permalink.ValidID(permalink.ID(a)))
+	assert.False(t, permalink.ValidID("../../etc"))
+}
+
+func TestNewServicer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, err := permalink.NewBoundedStore("", 10)
+	require.NoError(t, err)
+
+	_, err = permalink.NewServicer(nil, store)
+	var e cat.ErrNilParam
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "Servicer", e.Parameter)
+
+	_, err = permalink.NewServicer(mockcat.NewMockServicer(ctrl), nil)
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "Store", e.Parameter)
+}
+
+func TestServicer_GetImageAndFact(t *testing.T) {
+	t.Run("Sets the ID and stores the result", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		someResult := cat.CatResult{ImageURL: "http://someurl", Fact: "some-fact"}
+		next := mockcat.NewMockServicer(ctrl)
+		next.EXPECT().GetImageAndFact(gomock.Any()).Return(someResult, nil)
+
+		store, err := permalink.NewBoundedStore("", 10)
+		require.NoError(t, err)
+		s, err := permalink.NewServicer(next, store)
+		require.NoError(t, err)
+
+		c, err := s.GetImageAndFact(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, permalink.ID(someResult), c.ID)
+
+		got, err := store.Get(context.Background(), c.ID)
+		require.NoError(t, err)
+		assert.Equal(t, c, got)
+	})
+
+	t.Run("Serves the result without an ID given the store fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		someResult := cat.CatResult{ImageURL: "http://someurl", Fact: "some-fact"}
+		next := mockcat.NewMockServicer(ctrl)
+		next.EXPECT().GetImageAndFact(gomock.Any()).Return(someResult, nil)
+
+		s, err := permalink.NewServicer(next, failingStore{})
+		require.NoError(t, err)
+
+		c, err := s.GetImageAndFact(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, someResult, c)
+	})
+
+	t.Run("Stores the result untranslated and translates it for the caller", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		someResult := cat.CatResult{ImageURL: "http://someurl", Fact: "some-fact"}
+		next := mockcat.NewMockServicer(ctrl)
+		next.EXPECT().GetImageAndFact(gomock.Any()).DoAndReturn(func(ctx context.Context) (cat.CatResult, error) {
+			assert.Empty(t, cat.LanguagesFromContext(ctx))
+			return someResult, nil
+		})
+		tr := translatorFunc(func(ctx context.Context, c cat.CatResult) cat.CatResult {
+			c.Translation = &cat.Translation{Lang: cat.LanguagesFromContext(ctx)[0], Fact: "some-translation"}
+			return c
+		})
+
+		store, err := permalink.NewBoundedStore("", 10)
+		require.NoError(t, err)
+		s, err := permalink.NewServicer(next, store, permalink.WithTranslator(tr))
+		require.NoError(t, err)
+
+		c, err := s.GetImageAndFact(cat.WithLanguages(context.Background(), "de"))
+		require.NoError(t, err)
+		assert.Equal(t, &cat.Translation{Lang: "de", Fact: "some-translation"}, c.Translation)
+
+		got, err := store.Get(context.Background(), c.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.Translation)
+	})
+
+	t.Run("Returns the error from the next Servicer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		testErr := errors.New("some-error")
+		next := mockcat.NewMockServicer(ctrl)
+		next.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{}, testErr)
+
+		store, err := permalink.NewBoundedStore("", 10)
+		require.NoError(t, err)
+		s, err := permalink.NewServicer(next, store)
+		require.NoError(t, err)
+
+		_, err = s.GetImageAndFact(context.Background())
+		assert.True(t, errors.Is(err, testErr))
+	})
+}
+
+type failingStore struct{}
+
+func (failingStore) Put(context.Context, cat.CatResult) error {
+	return errors.New("some-error")
+}
+
+func (failingStore) Get(context.Context, string) (cat.CatResult, error) {
+	return cat.CatResult{}, permalink.ErrNotFound
+}
+
+type translatorFunc func(ctx context.Context, c cat.CatResult) cat.CatResult
+
+func (f translatorFunc) TranslateResult(ctx context.Context, c cat.CatResult) cat.CatResult {
+	return f(ctx, c)
+}
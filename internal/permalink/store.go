@@ -0,0 +1,370 @@
+package permalink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// BoundedStore keeps the most recently stored capacity results, evicting the
+// oldest first. With a path it appends every new result to a log file that is
+// replayed on start and rewritten once it holds twice capacity entries.
+type BoundedStore struct {
+	capacity int
+	path     string
+
+	// images and imageURL are where results' images served by this
+	// server come from; see WithImages.
+	images   *cat.ImageCache
+	imageURL string
+
+	mu      sync.RWMutex
+	byID    map[string]cat.CatResult
+	order   []string
+	f       *os.File
+	records int
+	// written counts appends, so Put can wait for its own to be synced.
+	written uint64
+	// kept is the images stored results link to, by image ID.
+	kept map[string]*keptImage
+
+	// syncMu lets concurrent Puts share one fsync, without holding mu
+	// while it runs. synced is the last append known to be on disk.
+	syncMu sync.Mutex
+	synced uint64
+}
+
+// keptImage is an image kept for as long as refs stored results link to it.
+// Its bytes are in data, or a file in the images directory with a path.
+type keptImage struct {
+	refs     int
+	mimeType string
+	data     []byte
+}
+
+type BoundedStoreOption func(s *BoundedStore)
+
+// WithImages keeps the images of stored results whose URL starts with
+// urlPrefix, taken from c, for as long as the results are stored. Without
+// it, links to them break once c drops the image. Images are kept next to
+// the log, or in memory without one.
+func WithImages(c *cat.ImageCache, urlPrefix string) BoundedStoreOption {
+	return func(s *BoundedStore) {
+		s.images = c
+		s.imageURL = urlPrefix
+	}
+}
+
+// NewBoundedStore returns a store holding up to capacity results. An empty
+// path keeps them in memory only.
+func NewBoundedStore(path string, capacity int, opts ...BoundedStoreOption) (*BoundedStore, error) {
+	if capacity < 1 {
+		return nil, errors.New("capacity must be at least 1")
+	}
+
+	s := &BoundedStore{
+		capacity: capacity,
+		path:     path,
+		byID:     make(map[string]cat.CatResult),
+		kept:     make(map[string]*keptImage),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	if s.images != nil {
+		if err := os.MkdirAll(s.imageDir(), 0700); err != nil {
+			return nil, fmt.Errorf("creating permalink image dir: %w", err)
+		}
+	}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := s.rewrite(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *BoundedStore) replay() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening permalink log: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var c cat.CatResult
+		if err := json.Unmarshal(sc.Bytes(), &c); err != nil || c.ID == "" {
+			// Torn write from a crash, the rewrite below drops it.
+			continue
+		}
+		if id := s.imageID(c); id != "" && s.kept[id] == nil {
+			if _, err := os.Stat(filepath.Join(s.imageDir(), id)); err == nil {
+				k := &keptImage{}
+				if c.Image != nil {
+					k.mimeType = c.Image.MIMEType
+				}
+				s.kept[id] = k
+			}
+		}
+		s.add(c)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return s.sweepImages()
+}
+
+// sweepImages removes image files nothing links to, left by a crash between
+// writing one and logging its result.
+func (s *BoundedStore) sweepImages() error {
+	if s.images == nil {
+		return nil
+	}
+	files, err := os.ReadDir(s.imageDir())
+	if err != nil {
+		return fmt.Errorf("reading permalink image dir: %w", err)
+	}
+	for _, f := range files {
+		if _, ok := s.kept[f.Name()]; !ok {
+			_ = os.Remove(filepath.Join(s.imageDir(), f.Name()))
+		}
+	}
+	return nil
+}
+
+// add records c in memory, evicting the oldest entry when full. It reports
+// whether c was new. Callers hold mu.
+func (s *BoundedStore) add(c cat.CatResult) bool {
+	if _, ok := s.byID[c.ID]; ok {
+		return false
+	}
+	// Count c's link first, so evicting a result with the same image
+	// doesn't drop it.
+	if k, ok := s.kept[s.imageID(c)]; ok {
+		k.refs++
+	}
+	if len(s.order) >= s.capacity {
+		s.release(s.byID[s.order[0]])
+		delete(s.byID, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.byID[c.ID] = c
+	s.order = append(s.order, c.ID)
+	return true
+}
+
+// imageID returns the ID of c's image if it is one of ours, or "".
+func (s *BoundedStore) imageID(c cat.CatResult) string {
+	if s.images == nil || !strings.HasPrefix(string(c.ImageURL), s.imageURL) {
+		return ""
+	}
+	id := strings.TrimPrefix(string(c.ImageURL), s.imageURL)
+	if id == "" || strings.Trim(id, "0123456789abcdef") != "" {
+		return ""
+	}
+	return id
+}
+
+// keep starts keeping c's image, if it is one of ours and isn't kept
+// already, so add can count c as linking to it. Callers hold mu.
+func (s *BoundedStore) keep(c cat.CatResult) error {
+	id := s.imageID(c)
+	if id == "" || s.kept[id] != nil {
+		return nil
+	}
+	data, mt, ok := s.images.Get(id)
+	if !ok {
+		return nil
+	}
+	k := &keptImage{mimeType: mt}
+	if s.path == "" {
+		k.data = data
+	} else if err := os.WriteFile(filepath.Join(s.imageDir(), id), data, 0600); err != nil {
+		return fmt.Errorf("writing permalink image: %w", err)
+	}
+	s.kept[id] = k
+	return nil
+}
+
+// release drops c's link to its image, and the image once nothing links to
+// it. Callers hold mu.
+func (s *BoundedStore) release(c cat.CatResult) {
+	id := s.imageID(c)
+	k, ok := s.kept[id]
+	if !ok {
+		return
+	}
+	if k.refs--; k.refs > 0 {
+		return
+	}
+	delete(s.kept, id)
+	if s.path != "" {
+		_ = os.Remove(filepath.Join(s.imageDir(), id))
+	}
+}
+
+func (s *BoundedStore) imageDir() string {
+	return s.path + ".images"
+}
+
+// Image returns an image kept for a stored result, by its ID in the
+// ImageCache given to WithImages.
+func (s *BoundedStore) Image(id string) ([]byte, string, bool) {
+	s.mu.RLock()
+	k, ok := s.kept[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, "", false
+	}
+	if k.data != nil {
+		return k.data, k.mimeType, true
+	}
+	b, err := os.ReadFile(filepath.Join(s.imageDir(), id))
+	if err != nil {
+		return nil, "", false
+	}
+	if k.mimeType == "" {
+		// Replayed for a result that didn't say.
+		return b, http.DetectContentType(b), true
+	}
+	return b, k.mimeType, true
+}
+
+// rewrite replaces the log with the live entries. Callers hold mu.
+func (s *BoundedStore) rewrite() error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("creating permalink log: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, id := range s.order {
+		if err := enc.Encode(s.byID[id]); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("writing permalink log: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing permalink log: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		f.Close()
+		return fmt.Errorf("replacing permalink log: %w", err)
+	}
+
+	if s.f != nil {
+		_ = s.f.Close()
+	}
+	s.f = f
+	s.records = len(s.order)
+	return nil
+}
+
+func (s *BoundedStore) Put(_ context.Context, c cat.CatResult) error {
+	if c.ID == "" {
+		return cat.ErrNilParam{Parameter: "ID"}
+	}
+
+	seq, err := s.put(c)
+	if err != nil {
+		return err
+	}
+	return s.sync(seq)
+}
+
+// put adds c and appends it to the log, returning its sequence number for
+// sync, or 0 if there is nothing to sync.
+func (s *BoundedStore) put(c cat.CatResult) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byID[c.ID]; ok {
+		return 0, nil
+	}
+	if err := s.keep(c); err != nil {
+		return 0, err
+	}
+	s.add(c)
+	if s.f == nil {
+		return 0, nil
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.f.Write(append(b, '\n')); err != nil {
+		return 0, fmt.Errorf("writing permalink log: %w", err)
+	}
+	s.records++
+	s.written++
+	if s.records >= 2*s.capacity {
+		return s.written, s.rewrite()
+	}
+	return s.written, nil
+}
+
+// sync makes sure the log is on disk up to append seq. Concurrent callers
+// share one fsync, and mu isn't held while it runs.
+func (s *BoundedStore) sync(seq uint64) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	if seq == 0 || s.synced >= seq {
+		return nil
+	}
+
+	s.mu.RLock()
+	f, written := s.f, s.written
+	s.mu.RUnlock()
+	// A file closed since is one rewrite replaced with a synced copy.
+	if err := f.Sync(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return fmt.Errorf("syncing permalink log: %w", err)
+	}
+	s.synced = written
+	return nil
+}
+
+func (s *BoundedStore) Get(_ context.Context, id string) (cat.CatResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.byID[id]
+	if !ok {
+		return cat.CatResult{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (s *BoundedStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	return s.f.Close()
+}
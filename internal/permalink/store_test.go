@@ -0,0 +1,144 @@
+package permalink_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/permalink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func result(n int) cat.CatResult {
+	c := cat.CatResult{ImageURL: cat.ImageURL(fmt.Sprintf("http://someurl/%d", n)), Fact: "some-fact"}
+	c.ID = permalink.ID(c)
+	return c
+}
+
+func TestNewBoundedStore(t *testing.T) {
+	s, err := permalink.NewBoundedStore("", 0)
+
+	assert.Nil(t, s)
+	assert.Error(t, err)
+}
+
+func TestBoundedStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Evicts the oldest result once full", func(t *testing.T) {
+		s, err := permalink.NewBoundedStore("", 2)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, s.Put(ctx, result(i)))
+		}
+
+		_, err = s.Get(ctx, result(0).ID)
+		assert.True(t, errors.Is(err, permalink.ErrNotFound))
+		got, err := s.Get(ctx, result(2).ID)
+		require.NoError(t, err)
+		assert.Equal(t, result(2), got)
+	})
+
+	t.Run("Returns an error given a result without an ID", func(t *testing.T) {
+		s, err := permalink.NewBoundedStore("", 2)
+		require.NoError(t, err)
+
+		assert.Error(t, s.Put(ctx, cat.CatResult{Fact: "some-fact"}))
+	})
+
+	t.Run("Survives a restart and keeps the log bounded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "permalinks.log")
+		s, err := permalink.NewBoundedStore(path, 3)
+		require.NoError(t, err)
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, s.Put(ctx, result(i)))
+			require.NoError(t, s.Put(ctx, result(i)))
+		}
+		require.NoError(t, s.Close())
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Less(t, strings.Count(string(b), "\n"), 6)
+
+		s, err = permalink.NewBoundedStore(path, 3)
+		require.NoError(t, err)
+		defer s.Close()
+
+		for i := 7; i < 10; i++ {
+			got, err := s.Get(ctx, result(i).ID)
+			require.NoError(t, err)
+			assert.Equal(t, result(i), got)
+		}
+		_, err = s.Get(ctx, result(6).ID)
+		assert.True(t, errors.Is(err, permalink.ErrNotFound))
+	})
+	t.Run("Keeps a result's image once the cache drops it, across a restart", func(t *testing.T) {
+		images, err := cat.NewImageCache(cat.MaxBinaryImageSize)
+		require.NoError(t, err)
+		id := images.Put([]byte("some-image"), "image/png")
+		u := cat.ImageURL("https://cats.example/images/" + id)
+		c := cat.CatResult{ImageURL: u, Image: &cat.Image{URL: u, MIMEType: "image/png"}, Fact: "some-fact"}
+		c.ID = permalink.ID(c)
+
+		path := filepath.Join(t.TempDir(), "permalinks.log")
+		s, err := permalink.NewBoundedStore(path, 2, permalink.WithImages(images, "https://cats.example/images/"))
+		require.NoError(t, err)
+		require.NoError(t, s.Put(ctx, c))
+		require.NoError(t, s.Close())
+
+		// Evict it from the cache.
+		images.Put(make([]byte, cat.MaxBinaryImageSize), "image/png")
+		_, _, ok := images.Get(id)
+		require.False(t, ok)
+
+		s, err = permalink.NewBoundedStore(path, 2, permalink.WithImages(images, "https://cats.example/images/"))
+		require.NoError(t, err)
+		defer s.Close()
+		b, mt, ok := s.Image(id)
+		require.True(t, ok)
+		assert.Equal(t, "some-image", string(b))
+		assert.Equal(t, "image/png", mt)
+
+		// Once the result is evicted, so is its image.
+		require.NoError(t, s.Put(ctx, result(1)))
+		require.NoError(t, s.Put(ctx, result(2)))
+		_, _, ok = s.Image(id)
+		assert.False(t, ok)
+		files, err := os.ReadDir(path + ".images")
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("Syncs concurrent puts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "permalinks.log")
+		s, err := permalink.NewBoundedStore(path, 100)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, s.Put(ctx, result(i)))
+			}(i)
+		}
+		wg.Wait()
+		require.NoError(t, s.Close())
+
+		s, err = permalink.NewBoundedStore(path, 100)
+		require.NoError(t, err)
+		defer s.Close()
+		for i := 0; i < 50; i++ {
+			_, err := s.Get(ctx, result(i).ID)
+			assert.NoError(t, err)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+// Package problem writes RFC 7807 problem details bodies, so every error the
+// server answers with has the same shape wherever it comes from.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details body. Type is a relative URI naming
+// the kind of problem, e.g. "/problems/rate-limited", so clients can branch
+// on it.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+
+	Param   string   `json:"param,omitempty"`
+	Value   string   `json:"value,omitempty"`
+	Allowed []string `json:"allowed,omitempty"`
+}
+
+// Write answers with p. Title defaults to the status text.
+func Write(w http.ResponseWriter, p Problem) {
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,27 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"github.com/matthewjamesboyle/catserver/internal/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	rr := httptest.NewRecorder()
+	problem.Write(rr, problem.Problem{Type: "/problems/some-problem", Status: http.StatusTeapot, Detail: "some-detail"})
+
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	var got problem.Problem
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, problem.Problem{
+		Type:   "/problems/some-problem",
+		Title:  "I'm a teapot",
+		Status: http.StatusTeapot,
+		Detail: "some-detail",
+	}, got)
+}
@@ -0,0 +1,24 @@
+package quota
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"net/http"
+)
+
+type doer struct {
+	q    *Quota
+	next cat.Doer
+}
+
+// Doer wraps next so that every call is taken from q first. Calls over
+// budget fail with cat.ErrQuotaExhausted without reaching next.
+func Doer(q *Quota, next cat.Doer) cat.Doer {
+	return &doer{q: q, next: next}
+}
+
+func (d *doer) Do(req *http.Request) (*http.Response, error) {
+	if err := d.q.Take(req.Context()); err != nil {
+		return nil, err
+	}
+	return d.next.Do(req)
+}
@@ -0,0 +1,40 @@
+package quota_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/matthewjamesboyle/catserver/internal/quota"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDoer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	q, err := quota.NewQuota("image", quota.Config{Daily: 1}, "")
+	require.NoError(t, err)
+	md := mockcat.NewMockDoer(ctrl)
+	md.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`[{"url": "https://cdn.example/cat.jpg"}]`)),
+	}, nil).Times(1)
+
+	is, err := cat.NewImageService(quota.Doer(q, md), "http://some-url")
+	require.NoError(t, err)
+
+	_, err = is.GetImage(context.Background())
+	require.NoError(t, err)
+
+	_, err = is.GetImage(context.Background())
+	var e cat.ErrQuotaExhausted
+	require.True(t, errors.As(err, &e), "got %v", err)
+	assert.Equal(t, "day", e.Period)
+}
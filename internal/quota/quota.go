@@ -0,0 +1,295 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/ratelimit"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config is the budget for one upstream. Zero fields are unlimited.
+type Config struct {
+	// RPS and Burst limit the request rate, as a token bucket.
+	RPS   float64
+	Burst int
+	// Daily and Monthly cap requests per calendar day and month.
+	Daily   int
+	Monthly int
+	// Pace spreads the daily and monthly quotas evenly over their period,
+	// so they last until the end of it.
+	Pace bool
+	// MaxWait is how long a request may be held back to keep to the rate
+	// or pace before it gives up with cat.ErrQuotaExhausted.
+	MaxWait time.Duration
+	// Location decides where days and months start. Defaults to UTC.
+	Location *time.Location
+}
+
+// Usage is how many requests have been made in the current day and month.
+type Usage struct {
+	Day        string `json:"day"`
+	DayCount   int    `json:"day_count"`
+	Month      string `json:"month"`
+	MonthCount int    `json:"month_count"`
+}
+
+// Quota keeps an upstream within its Config.
+type Quota struct {
+	upstream string
+	cfg      Config
+	path     string
+	rate     *ratelimit.Limiter
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+
+	mu    sync.Mutex
+	usage Usage
+	dirty bool
+
+	// saveMu keeps writes to path in order without holding up Take.
+	saveMu sync.Mutex
+}
+
+// NewQuota loads usage saved at path, if any. An empty path keeps it in
+// memory, so it starts from zero on every restart. Usage is only written
+// back by Save, which Persist calls periodically.
+func NewQuota(upstream string, cfg Config, path string) (*Quota, error) {
+	if cfg.RPS < 0 || cfg.Burst < 0 || cfg.Daily < 0 || cfg.Monthly < 0 || cfg.MaxWait < 0 {
+		return nil, errors.New("quota config must not be negative")
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+
+	q := &Quota{upstream: upstream, cfg: cfg, path: path, now: time.Now, sleep: sleep}
+	if cfg.RPS > 0 {
+		rl, err := ratelimit.NewLimiter(cfg.RPS, max(cfg.Burst, 1))
+		if err != nil {
+			return nil, err
+		}
+		q.rate = rl
+	}
+	if path == "" {
+		return q, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading quota usage: %w", err)
+	}
+	if err := json.Unmarshal(b, &q.usage); err != nil {
+		return nil, fmt.Errorf("decoding quota usage: %w", err)
+	}
+	return q, nil
+}
+
+// Take uses up one request, waiting up to MaxWait if the rate or pace
+// require it. It returns cat.ErrQuotaExhausted if the request can't be made
+// in that time.
+func (q *Quota) Take(ctx context.Context) error {
+	// Check the day and month first, so a call they refuse doesn't use up
+	// a rate token.
+	if err := q.waitQuota(ctx, false); err != nil {
+		return err
+	}
+	if err := q.takeRate(ctx); err != nil {
+		return err
+	}
+	return q.waitQuota(ctx, true)
+}
+
+// Usage returns the requests made so far this day and month.
+func (q *Quota) Usage() Usage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll(q.now().In(q.cfg.Location))
+	return q.usage
+}
+
+func (q *Quota) takeRate(ctx context.Context) error {
+	if q.rate == nil {
+		return nil
+	}
+	for {
+		d := q.rate.Allow(q.upstream)
+		if d.Allowed {
+			return nil
+		}
+		if d.RetryAfter > q.cfg.MaxWait {
+			return cat.ErrQuotaExhausted{Upstream: q.upstream, Period: "second", RetryAfter: d.RetryAfter}
+		}
+		if err := q.sleep(ctx, d.RetryAfter); err != nil {
+			return err
+		}
+	}
+}
+
+// waitQuota waits until takeQuota allows the request, counting it if take.
+func (q *Quota) waitQuota(ctx context.Context, take bool) error {
+	for {
+		wait, err := q.takeQuota(take)
+		if err != nil || wait == 0 {
+			return err
+		}
+		if err := q.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// takeQuota checks the day and month allow a request, counting it if take,
+// or returns how long to wait before trying again.
+func (q *Quota) takeQuota(take bool) (time.Duration, error) {
+	if q.cfg.Daily == 0 && q.cfg.Monthly == 0 {
+		return 0, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now().In(q.cfg.Location)
+	q.roll(now)
+
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, q.cfg.Location)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, q.cfg.Location)
+	var wait time.Duration
+	for _, p := range []struct {
+		name       string
+		limit      int
+		used       int
+		start, end time.Time
+	}{
+		{"day", q.cfg.Daily, q.usage.DayCount, dayStart, dayStart.AddDate(0, 0, 1)},
+		{"month", q.cfg.Monthly, q.usage.MonthCount, monthStart, monthStart.AddDate(0, 1, 0)},
+	} {
+		if p.limit == 0 {
+			continue
+		}
+		if p.used >= p.limit {
+			return 0, cat.ErrQuotaExhausted{Upstream: q.upstream, Period: p.name, RetryAfter: p.end.Sub(now)}
+		}
+		if !q.cfg.Pace {
+			continue
+		}
+		w := paceWait(p.limit, p.used, p.start, p.end, now)
+		if w > q.cfg.MaxWait {
+			return 0, cat.ErrQuotaExhausted{Upstream: q.upstream, Period: p.name, RetryAfter: w}
+		}
+		wait = max(wait, w)
+	}
+	if wait > 0 {
+		return wait, nil
+	}
+	if take {
+		q.usage.DayCount++
+		q.usage.MonthCount++
+		q.dirty = true
+	}
+	return 0, nil
+}
+
+// paceWait is how long until used is within the share of limit that has
+// accrued between start and now, plus a little slack so short bursts still
+// get through.
+func paceWait(limit, used int, start, end, now time.Time) time.Duration {
+	period := end.Sub(start)
+	slack := max(1, limit/100)
+	if used < slack {
+		return 0
+	}
+	due := start.Add(time.Duration(math.Ceil(float64(period) * float64(used-slack+1) / float64(limit))))
+	if !due.After(now) {
+		return 0
+	}
+	return due.Sub(now)
+}
+
+// roll starts new counts when the day or month changes. Callers hold mu.
+func (q *Quota) roll(now time.Time) {
+	if day := now.Format("2006-01-02"); q.usage.Day != day {
+		q.usage.Day, q.usage.DayCount = day, 0
+	}
+	if month := now.Format("2006-01"); q.usage.Month != month {
+		q.usage.Month, q.usage.MonthCount = month, 0
+	}
+}
+
+// Save writes the usage atomically if it has changed since the last Save.
+// Requests are allowed whether or not it succeeds: losing count on a
+// restart is better than refusing every call because the disk is unhappy.
+func (q *Quota) Save() error {
+	if q.path == "" {
+		return nil
+	}
+	q.saveMu.Lock()
+	defer q.saveMu.Unlock()
+
+	q.mu.Lock()
+	usage, dirty := q.usage, q.dirty
+	q.dirty = false
+	q.mu.Unlock()
+	if !dirty {
+		return nil
+	}
+
+	err := func() error {
+		b, err := json.Marshal(usage)
+		if err != nil {
+			return err
+		}
+		tmp := q.path + ".tmp"
+		if err := os.WriteFile(tmp, b, 0600); err != nil {
+			return fmt.Errorf("writing quota usage: %w", err)
+		}
+		if err := os.Rename(tmp, q.path); err != nil {
+			return fmt.Errorf("replacing quota usage: %w", err)
+		}
+		return nil
+	}()
+	if err != nil {
+		// Try again next time.
+		q.mu.Lock()
+		q.dirty = true
+		q.mu.Unlock()
+	}
+	return err
+}
+
+// Persist calls Save every interval until done is closed, so usage is
+// written in batches rather than on every request. Call Save once more on
+// shutdown to keep the last of it.
+func (q *Quota) Persist(done <-chan struct{}, interval time.Duration, onError func(error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			if err := q.Save(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,148 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t     time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) sleep(_ context.Context, d time.Duration) error {
+	c.slept = append(c.slept, d)
+	c.t = c.t.Add(d)
+	return nil
+}
+
+func newTestQuota(t *testing.T, cfg Config, path string, at time.Time) (*Quota, *fakeClock) {
+	t.Helper()
+
+	q, err := NewQuota("image", cfg, path)
+	require.NoError(t, err)
+	c := &fakeClock{t: at}
+	q.now, q.sleep = c.now, c.sleep
+	return q, c
+}
+
+func exhausted(t *testing.T, err error) cat.ErrQuotaExhausted {
+	t.Helper()
+
+	var e cat.ErrQuotaExhausted
+	require.True(t, errors.As(err, &e), "got %v", err)
+	return e
+}
+
+func TestNewQuota(t *testing.T) {
+	q, err := NewQuota("image", Config{Daily: -1}, "")
+
+	assert.Nil(t, q)
+	assert.Error(t, err)
+}
+
+func TestQuota_Take(t *testing.T) {
+	noon := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Is unlimited by default", func(t *testing.T) {
+		q, _ := newTestQuota(t, Config{}, "", noon)
+
+		for i := 0; i < 100; i++ {
+			require.NoError(t, q.Take(context.Background()))
+		}
+	})
+
+	t.Run("Fails once the rate is exceeded and waiting isn't allowed", func(t *testing.T) {
+		q, _ := newTestQuota(t, Config{RPS: 0.1, Burst: 1}, "", noon)
+
+		require.NoError(t, q.Take(context.Background()))
+		e := exhausted(t, q.Take(context.Background()))
+		assert.Equal(t, "image", e.Upstream)
+		assert.Equal(t, "second", e.Period)
+		assert.Greater(t, e.RetryAfter, 9*time.Second)
+	})
+
+	t.Run("Stops at the daily quota until the next day", func(t *testing.T) {
+		q, c := newTestQuota(t, Config{Daily: 3}, "", noon)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, q.Take(context.Background()))
+		}
+		e := exhausted(t, q.Take(context.Background()))
+		assert.Equal(t, "day", e.Period)
+		assert.Equal(t, 12*time.Hour, e.RetryAfter)
+
+		c.t = c.t.Add(12 * time.Hour)
+		assert.NoError(t, q.Take(context.Background()))
+		assert.Equal(t, Usage{Day: "2026-10-20", DayCount: 1, Month: "2026-10", MonthCount: 4}, q.Usage())
+	})
+
+	t.Run("Stops at the monthly quota", func(t *testing.T) {
+		q, _ := newTestQuota(t, Config{Daily: 10, Monthly: 2}, "", noon)
+
+		require.NoError(t, q.Take(context.Background()))
+		require.NoError(t, q.Take(context.Background()))
+		assert.Equal(t, "month", exhausted(t, q.Take(context.Background())).Period)
+	})
+
+	t.Run("Paces the quota across the day", func(t *testing.T) {
+		midnight := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+		q, c := newTestQuota(t, Config{Daily: 24, Pace: true, MaxWait: 2 * time.Hour}, "", midnight)
+
+		require.NoError(t, q.Take(context.Background()))
+		require.NoError(t, q.Take(context.Background()))
+		assert.Equal(t, []time.Duration{time.Hour}, c.slept, "one an hour after the first")
+
+		q.cfg.MaxWait = 0
+		e := exhausted(t, q.Take(context.Background()))
+		assert.Equal(t, "day", e.Period)
+		assert.Equal(t, time.Hour, e.RetryAfter)
+	})
+
+	t.Run("Keeps usage across restarts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "image.json")
+		q, _ := newTestQuota(t, Config{Daily: 2}, path, noon)
+		require.NoError(t, q.Take(context.Background()))
+		require.NoError(t, q.Take(context.Background()))
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err), "usage is only written by Save")
+		require.NoError(t, q.Save())
+
+		q, _ = newTestQuota(t, Config{Daily: 2}, path, noon.Add(time.Hour))
+		exhausted(t, q.Take(context.Background()))
+	})
+
+	t.Run("Doesn't spend a rate token on a call the quota refuses", func(t *testing.T) {
+		q, _ := newTestQuota(t, Config{RPS: 0.1, Burst: 1, Daily: 1}, "", noon)
+		q.usage = Usage{Day: "2026-10-19", DayCount: 1, Month: "2026-10", MonthCount: 1}
+
+		assert.Equal(t, "day", exhausted(t, q.Take(context.Background())).Period)
+		assert.True(t, q.rate.Allow("image").Allowed, "the token is still there")
+	})
+}
+
+func TestQuota_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "image.json")
+	q, err := NewQuota("image", Config{Daily: 10}, path)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	defer close(done)
+	go q.Persist(done, 10*time.Millisecond, func(err error) { t.Error(err) })
+
+	require.NoError(t, q.Take(context.Background()))
+	assert.Eventually(t, func() bool {
+		b, err := os.ReadFile(path)
+		return err == nil && strings.Contains(string(b), `"day_count":1`)
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// KeyFunc picks the bucket a request is charged to.
+type KeyFunc func(req *http.Request) string
+
+// KeyByIP keys requests by client IP. With trustedHops > 0 the server sits
+// behind that many proxies that each append to X-Forwarded-For, so the
+// client is the trustedHops'th address from the right. Anything further left
+// was supplied by the client and is ignored.
+func KeyByIP(trustedHops int) KeyFunc {
+	return func(req *http.Request) string {
+		if trustedHops > 0 {
+			var hops []string
+			for _, h := range req.Header.Values("X-Forwarded-For") {
+				for _, ip := range strings.Split(h, ",") {
+					if ip = strings.TrimSpace(ip); ip != "" {
+						hops = append(hops, ip)
+					}
+				}
+			}
+			if len(hops) > 0 {
+				i := len(hops) - trustedHops
+				if i < 0 {
+					i = 0
+				}
+				return "ip:" + hops[i]
+			}
+		}
+
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		return "ip:" + host
+	}
+}
+
+// KeyByIdentity keys requests by the API key auth.Middleware validated,
+// falling back to fallback for requests it didn't authenticate. The raw
+// header is never used, or a client could get a fresh bucket per request by
+// sending a made up key each time.
+func KeyByIdentity(fallback KeyFunc) KeyFunc {
+	return func(req *http.Request) string {
+		if id, ok := auth.IdentityFromContext(req.Context()); ok && id.KeyID != "" {
+			return "key:" + id.KeyID
+		}
+		return fallback(req)
+	}
+}
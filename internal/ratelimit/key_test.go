@@ -0,0 +1,47 @@
+package ratelimit_test
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKeyByIP(t *testing.T) {
+	tests := []struct {
+		name        string
+		trustedHops int
+		xff         []string
+		want        string
+	}{
+		{name: "uses the remote address with no trusted hops", xff: []string{"1.1.1.1"}, want: "ip:192.0.2.1"},
+		{name: "uses the right most hop given one trusted proxy", trustedHops: 1, xff: []string{"6.6.6.6, 1.1.1.1"}, want: "ip:1.1.1.1"},
+		{name: "skips trusted proxies", trustedHops: 2, xff: []string{"6.6.6.6, 1.1.1.1", "10.0.0.1"}, want: "ip:1.1.1.1"},
+		{name: "uses the left most hop given fewer hops than trusted", trustedHops: 3, xff: []string{"1.1.1.1"}, want: "ip:1.1.1.1"},
+		{name: "uses the remote address given no header", trustedHops: 1, want: "ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+
+			assert.Equal(t, tt.want, ratelimit.KeyByIP(tt.trustedHops)(req))
+		})
+	}
+}
+
+func TestKeyByIdentity(t *testing.T) {
+	k := ratelimit.KeyByIdentity(ratelimit.KeyByIP(0))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "made-up-key")
+	assert.Equal(t, "ip:192.0.2.1", k(req), "an unvalidated key is ignored")
+
+	req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{KeyID: "k1"}))
+	assert.Equal(t, "key:k1", k(req))
+}
@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"container/list"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultIdleTTL is how long a bucket may go unused before it is evicted.
+const DefaultIdleTTL = 10 * time.Minute
+
+// DefaultMaxBuckets caps the number of keys tracked at once.
+const DefaultMaxBuckets = 100000
+
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a set of token buckets, one per key, each refilling at rate
+// tokens per second up to burst.
+type Limiter struct {
+	rate       float64
+	burst      int
+	idleTTL    time.Duration
+	maxBuckets int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	// lru holds the buckets, most recently used at the front.
+	lru *list.List
+}
+
+// Decision is the outcome of a call to Allow.
+type Decision struct {
+	Allowed bool
+	Limit   int
+	// Remaining is the number of whole tokens left after this request.
+	Remaining int
+	// RetryAfter is how long until a token is available. Zero if Allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+func NewLimiter(rate float64, burst int) (*Limiter, error) {
+	if rate <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+	if burst < 1 {
+		return nil, errors.New("burst must be at least 1")
+	}
+	return &Limiter{
+		rate:       rate,
+		burst:      burst,
+		idleTTL:    DefaultIdleTTL,
+		maxBuckets: DefaultMaxBuckets,
+		now:        time.Now,
+		buckets:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}, nil
+}
+
+// Allow takes a token from key's bucket if one is available.
+func (l *Limiter) Allow(key string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweepIdle(now)
+
+	var b *bucket
+	if e, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(e)
+		b = e.Value.(*bucket)
+	} else {
+		l.evict()
+		b = &bucket{key: key, tokens: float64(l.burst), last: now}
+		l.buckets[key] = l.lru.PushFront(b)
+	}
+
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	d := Decision{Limit: l.burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.durationFor(1 - b.tokens)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = l.durationFor(float64(l.burst) - b.tokens)
+	return d
+}
+
+// Len returns the number of buckets currently tracked.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+func (l *Limiter) durationFor(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.rate * float64(time.Second)))
+}
+
+// sweepIdle drops buckets that have been idle for longer than idleTTL. A
+// bucket idle for that long has refilled anyway, so dropping it changes
+// nothing. The least recently used are at the back, so it stops at the first
+// one still in use.
+func (l *Limiter) sweepIdle(now time.Time) {
+	for e := l.lru.Back(); e != nil; e = l.lru.Back() {
+		if now.Sub(e.Value.(*bucket).last) < l.idleTTL {
+			return
+		}
+		l.remove(e)
+	}
+}
+
+// evict makes room for a new bucket by dropping the least recently used
+// once maxBuckets is reached.
+func (l *Limiter) evict() {
+	for l.lru.Len() >= l.maxBuckets {
+		l.remove(l.lru.Back())
+	}
+}
+
+func (l *Limiter) remove(e *list.Element) {
+	l.lru.Remove(e)
+	delete(l.buckets, e.Value.(*bucket).key)
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter(t *testing.T, rate float64, burst int) (*Limiter, *fakeClock) {
+	t.Helper()
+
+	l, err := NewLimiter(rate, burst)
+	require.NoError(t, err)
+	c := &fakeClock{t: time.Unix(0, 0)}
+	l.now = c.now
+	return l, c
+}
+
+func TestNewLimiter(t *testing.T) {
+	t.Run("Returns an error given a non positive rate", func(t *testing.T) {
+		_, err := NewLimiter(0, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("Returns an error given a burst below one", func(t *testing.T) {
+		_, err := NewLimiter(1, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Run("Allows burst requests then rejects with a retry after", func(t *testing.T) {
+		l, _ := newTestLimiter(t, 1, 2)
+
+		assert.True(t, l.Allow("a").Allowed)
+		d := l.Allow("a")
+		assert.True(t, d.Allowed)
+		assert.Equal(t, 0, d.Remaining)
+		assert.Equal(t, 2*time.Second, d.Reset)
+
+		d = l.Allow("a")
+		assert.False(t, d.Allowed)
+		assert.Equal(t, time.Second, d.RetryAfter)
+	})
+
+	t.Run("Refills over time", func(t *testing.T) {
+		l, c := newTestLimiter(t, 2, 1)
+
+		assert.True(t, l.Allow("a").Allowed)
+		assert.False(t, l.Allow("a").Allowed)
+
+		c.t = c.t.Add(500 * time.Millisecond)
+		assert.True(t, l.Allow("a").Allowed)
+	})
+
+	t.Run("Keeps keys independent", func(t *testing.T) {
+		l, _ := newTestLimiter(t, 1, 1)
+
+		assert.True(t, l.Allow("a").Allowed)
+		assert.True(t, l.Allow("b").Allowed)
+		assert.False(t, l.Allow("a").Allowed)
+	})
+
+	t.Run("Evicts idle buckets", func(t *testing.T) {
+		l, c := newTestLimiter(t, 1, 1)
+
+		l.Allow("a")
+		l.Allow("b")
+		require.Equal(t, 2, l.Len())
+
+		c.t = c.t.Add(DefaultIdleTTL)
+		l.Allow("c")
+		assert.Equal(t, 1, l.Len())
+	})
+
+	t.Run("Never tracks more than maxBuckets keys", func(t *testing.T) {
+		l, c := newTestLimiter(t, 1, 1)
+		l.maxBuckets = 3
+
+		for i := 0; i < 10; i++ {
+			c.t = c.t.Add(time.Millisecond)
+			l.Allow(fmt.Sprint(i))
+		}
+		assert.LessOrEqual(t, l.Len(), 3)
+	})
+}
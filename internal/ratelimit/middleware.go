@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/problem"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Middleware applies a Limiter per mux route.
+type Middleware struct {
+	key    KeyFunc
+	def    *Limiter
+	routes map[string]*Limiter
+}
+
+// NewMiddleware limits every route with def unless overridden with Route.
+// Routes limited by def share each client's bucket in it. A nil def leaves
+// routes without an override unlimited.
+func NewMiddleware(key KeyFunc, def *Limiter) (*Middleware, error) {
+	if key == nil {
+		return nil, cat.ErrNilParam{Parameter: "key"}
+	}
+	return &Middleware{
+		key:    key,
+		def:    def,
+		routes: make(map[string]*Limiter),
+	}, nil
+}
+
+// Route sets the limiter for the route with the given path template. A nil
+// limiter exempts the route.
+func (m *Middleware) Route(tpl string, l *Limiter) {
+	m.routes[tpl] = l
+}
+
+// ParseRoutes reads per route limits from a comma separated list of
+// template=rps:burst, or template=off to exempt a route, e.g.
+// "/images/{id}=50:100,/c/{id}=20:40,/metrics=off". Each gets its own
+// Limiter, for Route.
+func ParseRoutes(spec string) (map[string]*Limiter, error) {
+	routes := make(map[string]*Limiter)
+	if strings.TrimSpace(spec) == "" {
+		return routes, nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		tpl, limit, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || tpl == "" {
+			return nil, fmt.Errorf("%q is not template=rps:burst", part)
+		}
+		if _, ok := routes[tpl]; ok {
+			return nil, fmt.Errorf("route %q given twice", tpl)
+		}
+		if limit == "off" {
+			routes[tpl] = nil
+			continue
+		}
+		rps, burst, ok := strings.Cut(limit, ":")
+		if !ok {
+			return nil, fmt.Errorf("%q is not template=rps:burst", part)
+		}
+		r, err := strconv.ParseFloat(rps, 64)
+		if err != nil {
+			return nil, fmt.Errorf("route %q: bad rps: %w", tpl, err)
+		}
+		b, err := strconv.Atoi(burst)
+		if err != nil {
+			return nil, fmt.Errorf("route %q: bad burst: %w", tpl, err)
+		}
+		l, err := NewLimiter(r, b)
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %w", tpl, err)
+		}
+		routes[tpl] = l
+	}
+	return routes, nil
+}
+
+func (m *Middleware) limiterFor(req *http.Request) *Limiter {
+	if r := mux.CurrentRoute(req); r != nil {
+		if tpl, err := r.GetPathTemplate(); err == nil {
+			if l, ok := m.routes[tpl]; ok {
+				return l
+			}
+		}
+	}
+	return m.def
+}
+
+// Handler is installed with mux.Router.Use.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		l := m.limiterFor(req)
+		if l == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		d := l.Allow(m.key(req))
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		w.Header().Set("RateLimit-Reset", seconds(d.Reset))
+
+		if !d.Allowed {
+			w.Header().Set("Retry-After", seconds(d.RetryAfter))
+			problem.Write(w, problem.Problem{
+				Type:   "/problems/rate-limited",
+				Status: http.StatusTooManyRequests,
+				Detail: "too many requests, retry in " + seconds(d.RetryAfter) + "s",
+			})
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// seconds rounds d up to whole seconds as the headers require.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,108 @@
+package ratelimit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/problem"
+	"github.com/matthewjamesboyle/catserver/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewMiddleware(t *testing.T) {
+	m, err := ratelimit.NewMiddleware(nil, nil)
+
+	assert.Nil(t, m)
+	var e cat.ErrNilParam
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "key", e.Parameter)
+}
+
+func TestMiddleware_Handler(t *testing.T) {
+	def, err := ratelimit.NewLimiter(1, 1)
+	require.NoError(t, err)
+	strict, err := ratelimit.NewLimiter(0.1, 1)
+	require.NoError(t, err)
+
+	m, err := ratelimit.NewMiddleware(ratelimit.KeyByIP(0), def)
+	require.NoError(t, err)
+	m.Route("/strict", strict)
+	m.Route("/metrics", nil)
+
+	r := mux.NewRouter()
+	r.Use(m.Handler)
+	ok := func(w http.ResponseWriter, _ *http.Request) {}
+	r.HandleFunc("/", ok)
+	r.HandleFunc("/strict", ok)
+	r.HandleFunc("/metrics", ok)
+
+	do := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	t.Run("Returns 429 with Retry-After once the bucket is empty", func(t *testing.T) {
+		rr := do("/")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "1", rr.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+
+		rr = do("/")
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+		var p problem.Problem
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&p))
+		assert.Equal(t, "/problems/rate-limited", p.Type)
+		assert.Equal(t, http.StatusTooManyRequests, p.Status)
+	})
+
+	t.Run("Uses the per route limiter", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do("/strict").Code)
+
+		rr := do("/strict")
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "10", rr.Header().Get("Retry-After"))
+	})
+
+	t.Run("Does not limit exempt routes", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			rr := do("/metrics")
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+		}
+	})
+}
+
+func TestParseRoutes(t *testing.T) {
+	t.Run("Gives each route its own limiter", func(t *testing.T) {
+		routes, err := ratelimit.ParseRoutes("/images/{id}=50:100, /metrics=off")
+		require.NoError(t, err)
+
+		require.Len(t, routes, 2)
+		require.NotNil(t, routes["/images/{id}"])
+		assert.Equal(t, 100, routes["/images/{id}"].Allow("some-key").Limit)
+		l, ok := routes["/metrics"]
+		assert.True(t, ok)
+		assert.Nil(t, l)
+	})
+
+	t.Run("Returns nothing given an empty spec", func(t *testing.T) {
+		routes, err := ratelimit.ParseRoutes("")
+		require.NoError(t, err)
+		assert.Empty(t, routes)
+	})
+
+	for _, spec := range []string{"/images", "/images=50", "/images=fast:100", "/images=50:0", "/a=1:1,/a=2:2"} {
+		t.Run("Returns an error given "+spec, func(t *testing.T) {
+			_, err := ratelimit.ParseRoutes(spec)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"time"
+)
+
+type factGetter struct {
+	r       *Router
+	getters map[string]cat.FactGetter
+}
+
+// NewFactGetter returns a FactGetter that asks r which of getters, by
+// provider name, to call each time.
+func NewFactGetter(r *Router, getters map[string]cat.FactGetter) (cat.FactGetter, error) {
+	if r == nil {
+		return nil, cat.ErrNilParam{Parameter: "Router"}
+	}
+	for _, n := range r.names {
+		if getters[n] == nil {
+			return nil, errors.New("no fact getter for provider " + n)
+		}
+	}
+	return factGetter{r: r, getters: getters}, nil
+}
+
+func (g factGetter) GetFact(ctx context.Context) (cat.Fact, error) {
+	f, _, err := g.GetSourcedFact(ctx)
+	return f, err
+}
+
+// GetSourcedFact returns the fact along with the provider that served it,
+// failing over to the next provider in the plan on error.
+func (g factGetter) GetSourcedFact(ctx context.Context) (cat.Fact, string, error) {
+	var err error
+	for _, name := range g.r.Plan() {
+		start := time.Now()
+		var f cat.Fact
+		f, err = g.getters[name].GetFact(ctx)
+		g.r.Observe(name, time.Since(start), err)
+		if err == nil {
+			return f, name, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return "", "", err
+}
+
+type imageGetter struct {
+	r       *Router
+	getters map[string]cat.ImageGetter
+}
+
+// NewImageGetter returns an ImageGetter that asks r which of getters, by
+// provider name, to call each time.
+func NewImageGetter(r *Router, getters map[string]cat.ImageGetter) (cat.ImageGetter, error) {
+	if r == nil {
+		return nil, cat.ErrNilParam{Parameter: "Router"}
+	}
+	for _, n := range r.names {
+		if getters[n] == nil {
+			return nil, errors.New("no image getter for provider " + n)
+		}
+	}
+	return imageGetter{r: r, getters: getters}, nil
+}
+
+// GetImage fails over to the next provider in the plan on error.
+func (g imageGetter) GetImage(ctx context.Context) (cat.Image, error) {
+	var err error
+	for _, name := range g.r.Plan() {
+		start := time.Now()
+		var i cat.Image
+		i, err = g.getters[name].GetImage(ctx)
+		g.r.Observe(name, time.Since(start), err)
+		if err == nil {
+			return i, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return cat.Image{}, err
+}
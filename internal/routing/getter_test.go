@@ -0,0 +1,91 @@
+package routing_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/matthewjamesboyle/catserver/internal/routing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewFactGetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, err := routing.NewRouter([]string{"a", "b"})
+	require.NoError(t, err)
+
+	_, err = routing.NewFactGetter(nil, nil)
+	assert.Error(t, err)
+	_, err = routing.NewFactGetter(r, map[string]cat.FactGetter{"a": mockcat.NewMockFactGetter(ctrl)})
+	assert.EqualError(t, err, "no fact getter for provider b")
+}
+
+func TestFactGetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, err := routing.NewRouter([]string{"a", "b"}, routing.WithExplore(0))
+	require.NoError(t, err)
+	a := mockcat.NewMockFactGetter(ctrl)
+	b := mockcat.NewMockFactGetter(ctrl)
+	g, err := routing.NewFactGetter(r, map[string]cat.FactGetter{"a": a, "b": b})
+	require.NoError(t, err)
+
+	// a is tried first and fails, so the call fails over to b, which is
+	// then preferred.
+	a.EXPECT().GetFact(gomock.Any()).Return(cat.Fact(""), errors.New("boom"))
+	b.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("from-b"), nil).Times(4)
+
+	for i := 0; i < 3; i++ {
+		f, err := g.GetFact(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, cat.Fact("from-b"), f)
+	}
+	assert.Equal(t, "b", r.Scores()[0].Name)
+
+	f, provider, err := cat.GetSourcedFact(context.Background(), g)
+	require.NoError(t, err)
+	assert.Equal(t, cat.Fact("from-b"), f)
+	assert.Equal(t, "b", provider)
+}
+
+func TestFactGetter_AllFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, err := routing.NewRouter([]string{"a", "b"}, routing.WithExplore(0))
+	require.NoError(t, err)
+	a := mockcat.NewMockFactGetter(ctrl)
+	b := mockcat.NewMockFactGetter(ctrl)
+	g, err := routing.NewFactGetter(r, map[string]cat.FactGetter{"a": a, "b": b})
+	require.NoError(t, err)
+
+	a.EXPECT().GetFact(gomock.Any()).Return(cat.Fact(""), errors.New("boom-a"))
+	b.EXPECT().GetFact(gomock.Any()).Return(cat.Fact(""), errors.New("boom-b"))
+
+	_, err = g.GetFact(context.Background())
+	assert.EqualError(t, err, "boom-b")
+}
+
+func TestImageGetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r, err := routing.NewRouter([]string{"only"})
+	require.NoError(t, err)
+	i := mockcat.NewMockImageGetter(ctrl)
+	g, err := routing.NewImageGetter(r, map[string]cat.ImageGetter{"only": i})
+	require.NoError(t, err)
+
+	i.EXPECT().GetImage(gomock.Any()).Return(cat.Image{URL: "some-url"}, nil)
+
+	img, err := g.GetImage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, cat.ImageURL("some-url"), img.URL)
+	assert.EqualValues(t, 1, r.Scores()[0].Requests)
+}
@@ -0,0 +1,234 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults for NewRouter.
+const (
+	DefaultDecay        = 0.2
+	DefaultExplore      = 0.1
+	DefaultErrorPenalty = 5 * time.Second
+)
+
+// Score is what a Router currently thinks of one provider.
+type Score struct {
+	Name string `json:"name"`
+	// Latency and ErrorRate are exponentially weighted moving averages.
+	Latency   time.Duration `json:"latency"`
+	ErrorRate float64       `json:"error_rate"`
+	// Cost is Latency plus ErrorRate times the router's error penalty, so a
+	// provider that fails fast never looks better than a slower one that
+	// works. The provider with the lowest cost gets most traffic.
+	Cost     time.Duration `json:"cost"`
+	Requests int64         `json:"requests"`
+	Best     bool          `json:"best"`
+}
+
+// MarshalJSON writes durations as strings like "120ms", for people reading
+// the admin endpoint.
+func (s Score) MarshalJSON() ([]byte, error) {
+	type score Score
+	return json.Marshal(struct {
+		score
+		Latency string `json:"latency"`
+		Cost    string `json:"cost"`
+	}{score(s), s.Latency.String(), s.Cost.String()})
+}
+
+type stats struct {
+	latency   float64
+	errorRate float64
+	requests  int64
+}
+
+// cost is the average latency in seconds, plus penalty seconds for every
+// failed call. Providers never called cost nothing, so each is tried
+// straight away.
+func (s *stats) cost(penalty float64) float64 {
+	if s.requests == 0 {
+		return 0
+	}
+	return s.latency + s.errorRate*penalty
+}
+
+// Router spreads calls between providers, sending most to whichever has the
+// lowest cost and a share of explore to the others, so that a provider that
+// recovers is noticed.
+type Router struct {
+	names   []string
+	decay   float64
+	explore float64
+	penalty float64
+
+	mu    sync.Mutex
+	stats map[string]*stats
+	rand  *rand.Rand
+}
+
+type Option func(r *Router)
+
+// WithErrorPenalty sets how much a failed call adds to a provider's cost.
+// It should be well above any healthy provider's latency.
+func WithErrorPenalty(penalty time.Duration) Option {
+	return func(r *Router) {
+		r.penalty = penalty.Seconds()
+	}
+}
+
+// WithDecay sets the weight, between 0 and 1, given to each new observation.
+func WithDecay(decay float64) Option {
+	return func(r *Router) {
+		r.decay = decay
+	}
+}
+
+// WithExplore sets the share of calls, between 0 and 1, spread among the
+// providers that aren't currently best.
+func WithExplore(explore float64) Option {
+	return func(r *Router) {
+		r.explore = explore
+	}
+}
+
+func NewRouter(names []string, opts ...Option) (*Router, error) {
+	if len(names) == 0 {
+		return nil, errors.New("router needs at least one provider")
+	}
+	r := &Router{
+		names:   append([]string(nil), names...),
+		decay:   DefaultDecay,
+		explore: DefaultExplore,
+		penalty: DefaultErrorPenalty.Seconds(),
+		stats:   make(map[string]*stats, len(names)),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, n := range names {
+		if _, ok := r.stats[n]; ok {
+			return nil, errors.New("duplicate provider " + n)
+		}
+		r.stats[n] = &stats{}
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	if r.decay <= 0 || r.decay > 1 {
+		return nil, errors.New("decay must be in (0, 1]")
+	}
+	if r.explore < 0 || r.explore >= 1 {
+		return nil, errors.New("explore must be in [0, 1)")
+	}
+	if r.penalty <= 0 {
+		return nil, errors.New("error penalty must be positive")
+	}
+	return r, nil
+}
+
+// Pick chooses the provider for the next call.
+func (r *Router) Pick() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	best := r.best()
+	if len(r.names) == 1 || r.rand.Float64() >= r.explore {
+		return best
+	}
+	others := make([]string, 0, len(r.names)-1)
+	for _, n := range r.names {
+		if n != best {
+			others = append(others, n)
+		}
+	}
+	return others[r.rand.Intn(len(others))]
+}
+
+// Plan returns the providers to try for the next call, in order: the one
+// Pick chooses, then the others from lowest cost, to fail over to.
+func (r *Router) Plan() []string {
+	first := r.Pick()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	plan := make([]string, 0, len(r.names))
+	for _, n := range r.names {
+		if n != first {
+			plan = append(plan, n)
+		}
+	}
+	sort.SliceStable(plan, func(i, j int) bool {
+		return r.stats[plan[i]].cost(r.penalty) < r.stats[plan[j]].cost(r.penalty)
+	})
+	return append([]string{first}, plan...)
+}
+
+// best returns the lowest cost provider, the first listed on a tie. Callers
+// hold mu.
+func (r *Router) best() string {
+	best := r.names[0]
+	for _, n := range r.names[1:] {
+		if r.stats[n].cost(r.penalty) < r.stats[best].cost(r.penalty) {
+			best = n
+		}
+	}
+	return best
+}
+
+// Observe records how a call to name went. Calls cancelled by the caller
+// say nothing about the provider and are ignored.
+func (r *Router) Observe(name string, latency time.Duration, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.stats[name]
+	if !ok {
+		return
+	}
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	if s.requests == 0 {
+		s.latency, s.errorRate = latency.Seconds(), failed
+	} else {
+		s.latency += r.decay * (latency.Seconds() - s.latency)
+		s.errorRate += r.decay * (failed - s.errorRate)
+	}
+	s.requests++
+}
+
+// Scores returns every provider's current score, best first.
+func (r *Router) Scores() []Score {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	best := r.best()
+	out := make([]Score, 0, len(r.names))
+	for _, n := range r.names {
+		s := r.stats[n]
+		out = append(out, Score{
+			Name:      n,
+			Latency:   seconds(s.latency),
+			ErrorRate: s.errorRate,
+			Cost:      seconds(s.cost(r.penalty)),
+			Requests:  s.requests,
+			Best:      n == best,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Best && !out[j].Best })
+	return out
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
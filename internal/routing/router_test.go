@@ -0,0 +1,98 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestNewRouter(t *testing.T) {
+	_, err := NewRouter(nil)
+	assert.Error(t, err)
+	_, err = NewRouter([]string{"a", "a"})
+	assert.Error(t, err)
+	_, err = NewRouter([]string{"a"}, WithDecay(0))
+	assert.Error(t, err)
+	_, err = NewRouter([]string{"a"}, WithExplore(1))
+	assert.Error(t, err)
+}
+
+func TestRouter(t *testing.T) {
+	t.Run("Tries every provider before it has observations", func(t *testing.T) {
+		r, err := NewRouter([]string{"a", "b"}, WithExplore(0))
+		require.NoError(t, err)
+
+		assert.Equal(t, "a", r.Pick())
+		r.Observe("a", 100*time.Millisecond, nil)
+		assert.Equal(t, "b", r.Pick())
+	})
+
+	t.Run("Sends most traffic to the lowest cost and explores the rest", func(t *testing.T) {
+		r, err := NewRouter([]string{"slow", "fast", "flaky"}, WithExplore(0.1))
+		require.NoError(t, err)
+		r.rand = rand.New(rand.NewSource(1))
+
+		r.Observe("slow", 500*time.Millisecond, nil)
+		r.Observe("fast", 50*time.Millisecond, nil)
+		r.Observe("flaky", 40*time.Millisecond, errors.New("boom"))
+
+		picks := map[string]int{}
+		for i := 0; i < 10000; i++ {
+			picks[r.Pick()]++
+		}
+		assert.InDelta(t, 9000, picks["fast"], 200)
+		assert.InDelta(t, 500, picks["slow"], 150)
+		assert.InDelta(t, 500, picks["flaky"], 150)
+	})
+
+	t.Run("Moves traffic as averages shift", func(t *testing.T) {
+		r, err := NewRouter([]string{"a", "b"}, WithExplore(0), WithDecay(0.5))
+		require.NoError(t, err)
+
+		r.Observe("a", 10*time.Millisecond, nil)
+		r.Observe("b", 20*time.Millisecond, nil)
+		assert.Equal(t, "a", r.Pick())
+
+		// a starts failing; one failure halves its success rate.
+		r.Observe("a", 12*time.Millisecond, errors.New("boom"))
+		assert.Equal(t, "b", r.Pick())
+
+		scores := r.Scores()
+		require.Len(t, scores, 2)
+		assert.Equal(t, Score{Name: "b", Latency: 20 * time.Millisecond, Cost: 20 * time.Millisecond, Requests: 1, Best: true}, scores[0])
+		assert.Equal(t, "a", scores[1].Name)
+		assert.InDelta(t, 0.5, scores[1].ErrorRate, 1e-9)
+		assert.Equal(t, 11*time.Millisecond, scores[1].Latency)
+		assert.Equal(t, 2511*time.Millisecond, scores[1].Cost)
+		assert.EqualValues(t, 2, scores[1].Requests)
+	})
+
+	t.Run("Prefers a slow healthy provider to a fast failing one", func(t *testing.T) {
+		r, err := NewRouter([]string{"broken", "healthy"}, WithExplore(0))
+		require.NoError(t, err)
+
+		for i := 0; i < 10; i++ {
+			r.Observe("broken", 2*time.Millisecond, errors.New("connection refused"))
+			r.Observe("healthy", 300*time.Millisecond, nil)
+		}
+
+		assert.Equal(t, "healthy", r.Pick())
+		assert.Equal(t, []string{"healthy", "broken"}, r.Plan())
+		assert.True(t, r.Scores()[0].Best)
+		assert.Equal(t, "healthy", r.Scores()[0].Name)
+	})
+
+	t.Run("Ignores calls the caller cancelled", func(t *testing.T) {
+		r, err := NewRouter([]string{"a"})
+		require.NoError(t, err)
+
+		r.Observe("a", time.Second, context.Canceled)
+		r.Observe("unknown", time.Second, nil)
+
+		assert.EqualValues(t, 0, r.Scores()[0].Requests)
+	})
+}
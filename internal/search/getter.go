@@ -0,0 +1,37 @@
+package search
+
+import (
+	"context"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+)
+
+type factGetter struct {
+	next cat.FactGetter
+	idx  *Index
+}
+
+// NewFactGetter returns a FactGetter that indexes every fact next returns.
+// It passes on which provider served each fact, if next says.
+func NewFactGetter(next cat.FactGetter, idx *Index) (cat.SourcedFactGetter, error) {
+	if next == nil {
+		return nil, cat.ErrNilParam{Parameter: "FactGetter"}
+	}
+	if idx == nil {
+		return nil, cat.ErrNilParam{Parameter: "Index"}
+	}
+	return factGetter{next: next, idx: idx}, nil
+}
+
+func (g factGetter) GetFact(ctx context.Context) (cat.Fact, error) {
+	f, _, err := g.GetSourcedFact(ctx)
+	return f, err
+}
+
+func (g factGetter) GetSourcedFact(ctx context.Context) (cat.Fact, string, error) {
+	f, provider, err := cat.GetSourcedFact(ctx, g.next)
+	if err != nil {
+		return "", "", err
+	}
+	g.idx.Add(string(f))
+	return f, provider, nil
+}
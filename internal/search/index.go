@@ -0,0 +1,277 @@
+package search
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BM25 parameters; the usual defaults.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// ErrEmptyQuery is returned when a query has no searchable terms, for example
+// when it is only stop words.
+var ErrEmptyQuery = errors.New("query has no searchable terms")
+
+// ErrIndexChanged is returned by SearchPage when the index has changed since
+// the generation being paged through, so offsets into it no longer line up.
+var ErrIndexChanged = errors.New("index changed since the first page")
+
+// Hit is a single search result.
+type Hit struct {
+	Text  string  `json:"fact"`
+	Score float64 `json:"score"`
+}
+
+type posting struct {
+	doc       int
+	positions []int
+}
+
+// Index is an in-memory inverted index over fact text, ranked with BM25.
+// It holds at most maxDocs facts, dropping the oldest to make room.
+type Index struct {
+	maxDocs int
+
+	mu sync.RWMutex
+	// gen counts the facts added, from 1, so pages can tell the ranking
+	// they are offsets into is unchanged.
+	gen uint64
+	// base is the document number of docs[0]. Document numbers only grow,
+	// so postings stay in document order and the oldest come first.
+	base     int
+	docs     []string
+	lengths  []int
+	total    int
+	seen     map[string]bool
+	postings map[string][]posting
+}
+
+// NewIndex returns an index of up to maxDocs facts. With maxDocs below 1 it
+// is unbounded.
+func NewIndex(maxDocs int) *Index {
+	return &Index{
+		maxDocs:  maxDocs,
+		gen:      1,
+		seen:     make(map[string]bool),
+		postings: make(map[string][]posting),
+	}
+}
+
+// Add indexes text unless an identical fact, ignoring case and spacing, is
+// already indexed. It reports whether text was added.
+func (i *Index) Add(text string) bool {
+	text = strings.Join(strings.Fields(text), " ")
+	k := strings.ToLower(text)
+	terms := tokenize(text)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(terms) == 0 || i.seen[k] {
+		return false
+	}
+	i.seen[k] = true
+	i.gen++
+	if i.maxDocs > 0 && len(i.docs) >= i.maxDocs {
+		i.evictOldest()
+	}
+
+	doc := i.base + len(i.docs)
+	i.docs = append(i.docs, text)
+	i.lengths = append(i.lengths, len(terms))
+	i.total += len(terms)
+
+	positions := make(map[string][]int)
+	for pos, t := range terms {
+		positions[t] = append(positions[t], pos)
+	}
+	for t, ps := range positions {
+		i.postings[t] = append(i.postings[t], posting{doc: doc, positions: ps})
+	}
+	return true
+}
+
+// evictOldest drops the oldest document. Callers hold mu.
+func (i *Index) evictOldest() {
+	text := i.docs[0]
+	for _, t := range unique(tokenize(text)) {
+		ps := i.postings[t]
+		if len(ps) > 0 && ps[0].doc == i.base {
+			ps = ps[1:]
+		}
+		if len(ps) == 0 {
+			delete(i.postings, t)
+		} else {
+			i.postings[t] = ps
+		}
+	}
+	delete(i.seen, strings.ToLower(text))
+	i.total -= i.lengths[0]
+	i.docs = i.docs[1:]
+	i.lengths = i.lengths[1:]
+	i.base++
+}
+
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+// Search ranks facts against q and returns limit of them starting at offset,
+// along with the total number that matched. Quoted phrases in q must appear
+// in a fact word for word; of the remaining terms, at least one must.
+func (i *Index) Search(q string, offset, limit int) ([]Hit, int, error) {
+	hits, total, _, err := i.SearchPage(q, 0, offset, limit)
+	return hits, total, err
+}
+
+// SearchPage is Search for paging through results. It also returns the
+// index's generation, which changes whenever a fact is added. Later pages
+// pass it back as gen, and get ErrIndexChanged if the index has changed
+// since; a gen of 0 is for the first page.
+func (i *Index) SearchPage(q string, gen uint64, offset, limit int) ([]Hit, int, uint64, error) {
+	pq := parseQuery(q)
+	if pq.empty() {
+		return nil, 0, 0, ErrEmptyQuery
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if gen != 0 && gen != i.gen {
+		return nil, 0, 0, ErrIndexChanged
+	}
+
+	candidates := i.candidates(pq)
+	scores := make(map[int]float64, len(candidates))
+	terms := pq.terms
+	for _, p := range pq.phrases {
+		terms = append(terms, p...)
+	}
+	for _, t := range unique(terms) {
+		idf := i.idf(t)
+		for _, p := range i.postings[t] {
+			if !candidates[p.doc] {
+				continue
+			}
+			scores[p.doc] += idf * i.tf(p)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		hits = append(hits, Hit{Text: i.docs[doc-i.base], Score: score})
+	}
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score == hits[b].Score {
+			return hits[a].Text < hits[b].Text
+		}
+		return hits[a].Score > hits[b].Score
+	})
+
+	total := len(hits)
+	if offset >= total {
+		return []Hit{}, total, i.gen, nil
+	}
+	hits = hits[offset:]
+	if limit < len(hits) {
+		hits = hits[:limit]
+	}
+	return hits, total, i.gen, nil
+}
+
+// candidates returns the documents that satisfy every phrase and, when there
+// are loose terms, contain at least one of them.
+func (i *Index) candidates(q query) map[int]bool {
+	var out map[int]bool
+	for _, p := range q.phrases {
+		docs := i.phrase(p)
+		if out == nil {
+			out = docs
+			continue
+		}
+		for d := range out {
+			if !docs[d] {
+				delete(out, d)
+			}
+		}
+	}
+	if len(q.terms) == 0 {
+		return out
+	}
+
+	matched := make(map[int]bool)
+	for _, t := range q.terms {
+		for _, p := range i.postings[t] {
+			if out == nil || out[p.doc] {
+				matched[p.doc] = true
+			}
+		}
+	}
+	return matched
+}
+
+// phrase returns the documents containing terms consecutively.
+func (i *Index) phrase(terms []string) map[int]bool {
+	// Positions of the first term, per document, narrowed down term by term
+	// to those where the phrase continues.
+	starts := make(map[int][]int)
+	for _, p := range i.postings[terms[0]] {
+		starts[p.doc] = p.positions
+	}
+	for n, t := range terms[1:] {
+		next := make(map[int][]int)
+		for _, p := range i.postings[t] {
+			ss, ok := starts[p.doc]
+			if !ok {
+				continue
+			}
+			at := make(map[int]bool, len(p.positions))
+			for _, pos := range p.positions {
+				at[pos] = true
+			}
+			for _, s := range ss {
+				if at[s+n+1] {
+					next[p.doc] = append(next[p.doc], s)
+				}
+			}
+		}
+		starts = next
+	}
+
+	out := make(map[int]bool, len(starts))
+	for d := range starts {
+		out[d] = true
+	}
+	return out
+}
+
+func (i *Index) idf(term string) float64 {
+	n := float64(len(i.docs))
+	df := float64(len(i.postings[term]))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+func (i *Index) tf(p posting) float64 {
+	f := float64(len(p.positions))
+	avg := float64(i.total) / float64(len(i.docs))
+	norm := 1 - b + b*float64(i.lengths[p.doc-i.base])/avg
+	return f * (k1 + 1) / (f + k1*norm)
+}
+
+func unique(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+package search_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/matthewjamesboyle/catserver/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func texts(hits []search.Hit) []string {
+	out := make([]string, len(hits))
+	for i, h := range hits {
+		out[i] = h.Text
+	}
+	return out
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := search.NewIndex(0)
+	assert.True(t, idx.Add("Cats use their whiskers to measure gaps."))
+	assert.True(t, idx.Add("A cat's whiskers are as wide as its body, and whiskers grow back."))
+	assert.True(t, idx.Add("Cats almost always land on their feet."))
+	assert.True(t, idx.Add("Cats sleep for 16 hours a day."))
+	assert.False(t, idx.Add("  cats sleep for 16 hours a DAY. "))
+	assert.Equal(t, 4, idx.Len())
+
+	t.Run("Ranks by BM25", func(t *testing.T) {
+		hits, total, err := idx.Search("whisker", 0, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, total)
+		assert.Equal(t, []string{
+			"A cat's whiskers are as wide as its body, and whiskers grow back.",
+			"Cats use their whiskers to measure gaps.",
+		}, texts(hits))
+		assert.Greater(t, hits[0].Score, hits[1].Score)
+	})
+
+	t.Run("Loose terms match any", func(t *testing.T) {
+		_, total, err := idx.Search("sleeping feet", 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("Phrases match word for word", func(t *testing.T) {
+		hits, _, err := idx.Search(`"land on their feet"`, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Cats almost always land on their feet."}, texts(hits))
+
+		hits, _, err = idx.Search(`"feet land"`, 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, hits)
+
+		hits, _, err = idx.Search(`"cats use" whiskers`, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Cats use their whiskers to measure gaps."}, texts(hits))
+	})
+
+	t.Run("Paginates", func(t *testing.T) {
+		all, total, err := idx.Search("cats", 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 4, total)
+
+		page, total, err := idx.Search("cats", 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 4, total)
+		assert.Equal(t, all[1:3], page)
+
+		page, _, err = idx.Search("cats", 10, 2)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+
+	t.Run("Rejects queries of only stop words", func(t *testing.T) {
+		_, _, err := idx.Search("the and", 0, 10)
+		assert.ErrorIs(t, err, search.ErrEmptyQuery)
+	})
+}
+
+func TestIndex_SearchPage(t *testing.T) {
+	idx := search.NewIndex(0)
+	idx.Add("Cats purr.")
+	idx.Add("Cats purr when content.")
+
+	first, _, gen, err := idx.SearchPage("purr", 0, 0, 1)
+	require.NoError(t, err)
+	next, _, nextGen, err := idx.SearchPage("purr", gen, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, gen, nextGen)
+	assert.NotEqual(t, first, next)
+
+	assert.False(t, idx.Add("Cats purr."), "a duplicate changes nothing")
+	_, _, _, err = idx.SearchPage("purr", gen, 1, 1)
+	require.NoError(t, err)
+
+	idx.Add("Kittens purr too.")
+	_, _, _, err = idx.SearchPage("purr", gen, 1, 1)
+	assert.ErrorIs(t, err, search.ErrIndexChanged)
+}
+
+func TestIndex_Add(t *testing.T) {
+	t.Run("Drops the oldest facts beyond maxDocs", func(t *testing.T) {
+		idx := search.NewIndex(2)
+		idx.Add("Cats purr.")
+		idx.Add("Cats purr when content.")
+		idx.Add("Kittens purr too.")
+
+		assert.Equal(t, 2, idx.Len())
+		hits, total, err := idx.Search("purr", 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.ElementsMatch(t, []string{"Cats purr when content.", "Kittens purr too."}, texts(hits))
+
+		assert.True(t, idx.Add("Cats purr."), "a dropped fact can be added again")
+		hits, _, err = idx.Search(`"kittens purr"`, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Kittens purr too."}, texts(hits))
+	})
+}
+
+func TestNewFactGetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, err := search.NewFactGetter(nil, search.NewIndex(0))
+	var e cat.ErrNilParam
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "FactGetter", e.Parameter)
+
+	g := mockcat.NewMockFactGetter(ctrl)
+	gomock.InOrder(
+		g.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("Cats purr."), nil),
+		g.EXPECT().GetFact(gomock.Any()).Return(cat.Fact(""), errors.New("boom")),
+	)
+	idx := search.NewIndex(0)
+	fg, err := search.NewFactGetter(g, idx)
+	require.NoError(t, err)
+
+	f, err := fg.GetFact(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, cat.Fact("Cats purr."), f)
+	_, err = fg.GetFact(context.Background())
+	assert.Error(t, err)
+
+	hits, _, err := idx.Search("purring", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Cats purr."}, texts(hits))
+}
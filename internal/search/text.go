@@ -0,0 +1,102 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true, "by": true,
+	"for": true, "from": true, "has": true, "have": true, "in": true, "is": true, "it": true, "its": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "their": true, "them": true, "they": true, "this": true,
+	"to": true, "was": true, "were": true, "will": true, "with": true,
+}
+
+// tokenize splits text into lowercased, stemmed terms, dropping stop words.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.TrimSuffix(strings.Trim(w, "'"), "'s")
+		if w == "" || stopWords[w] {
+			continue
+		}
+		terms = append(terms, stem(w))
+	}
+	return terms
+}
+
+// stem strips common English inflections so "purrs", "purring" and "purred"
+// all index alike. It is deliberately much simpler than a full Porter
+// stemmer; it only has to agree with itself.
+func stem(w string) string {
+	if len(w) <= 3 {
+		return w
+	}
+	return undouble(stripSuffix(w))
+}
+
+func stripSuffix(w string) string {
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+		return w
+	case strings.HasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed", "ly"} {
+		base := strings.TrimSuffix(w, suffix)
+		if base != w && len(base) >= 3 && hasVowel(base) {
+			return base
+		}
+	}
+	return w
+}
+
+// undouble drops a doubled final consonant, so "hopping" and "hop" meet at
+// "hop", and "purr" and "purring" at "pur".
+func undouble(w string) string {
+	n := len(w)
+	if n > 3 && w[n-1] == w[n-2] && !strings.ContainsRune("aeiouylsz", rune(w[n-1])) {
+		return w[:n-1]
+	}
+	return w
+}
+
+func hasVowel(w string) bool {
+	return strings.ContainsAny(w, "aeiouy")
+}
+
+// query is a parsed search: quoted phrases that must all match, and loose
+// terms of which at least one must.
+type query struct {
+	terms   []string
+	phrases [][]string
+}
+
+func parseQuery(q string) query {
+	var out query
+	for i, part := range strings.Split(q, `"`) {
+		terms := tokenize(part)
+		if len(terms) == 0 {
+			continue
+		}
+		// Odd parts sit between a pair of quotes.
+		if i%2 == 1 && len(terms) > 1 {
+			out.phrases = append(out.phrases, terms)
+			continue
+		}
+		out.terms = append(out.terms, terms...)
+	}
+	return out
+}
+
+func (q query) empty() bool {
+	return len(q.terms) == 0 && len(q.phrases) == 0
+}
@@ -0,0 +1,21 @@
+package search
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"cat", "pur", "when", "happy"}, tokenize("The cat's purring when it's HAPPY!"))
+	assert.Equal(t, []string{"whisker", "whisker", "hop", "pass", "fly"}, tokenize("whiskers, whisker; hopping passes flies"))
+	assert.Equal(t, []string{"pur", "pur", "pur", "pur"}, tokenize("purr purrs purred purring"))
+	assert.Empty(t, tokenize("the of and"))
+}
+
+func TestParseQuery(t *testing.T) {
+	q := parseQuery(`sleep "land on their feet" "the" whiskers`)
+
+	assert.Equal(t, []string{"sleep", "whisker"}, q.terms)
+	assert.Equal(t, [][]string{{"land", "feet"}}, q.phrases)
+	assert.True(t, parseQuery(`"the" of`).empty())
+}
@@ -0,0 +1,124 @@
+package session
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+type history struct {
+	id       string
+	seen     map[string]time.Time
+	lastUsed time.Time
+}
+
+// MemoryHistory is a cat.SessionHistory that counts a key as seen for window
+// after it was claimed. It tracks at most maxSessions sessions and
+// maxPerSession keys per session, dropping the least recently used first.
+type MemoryHistory struct {
+	window        time.Duration
+	maxSessions   int
+	maxPerSession int
+	now           func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*list.Element
+	// lru holds *history, most recently used at the front.
+	lru *list.List
+}
+
+func NewMemoryHistory(window time.Duration, maxSessions, maxPerSession int) (*MemoryHistory, error) {
+	if window <= 0 {
+		return nil, errors.New("window must be positive")
+	}
+	if maxSessions < 1 || maxPerSession < 1 {
+		return nil, errors.New("maxSessions and maxPerSession must be at least 1")
+	}
+	return &MemoryHistory{
+		window:        window,
+		maxSessions:   maxSessions,
+		maxPerSession: maxPerSession,
+		now:           time.Now,
+		sessions:      make(map[string]*list.Element),
+		lru:           list.New(),
+	}, nil
+}
+
+// Seen reports whether session has claimed key within the window.
+func (m *MemoryHistory) Seen(session, key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.sessions[session]
+	if !ok {
+		return false
+	}
+	at, ok := e.Value.(*history).seen[key]
+	return ok && m.now().Sub(at) < m.window
+}
+
+func (m *MemoryHistory) Claim(session, key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	e, ok := m.sessions[session]
+	if ok {
+		m.lru.MoveToFront(e)
+	} else {
+		m.evictSessions(now)
+		e = m.lru.PushFront(&history{id: session, seen: make(map[string]time.Time)})
+		m.sessions[session] = e
+	}
+	h := e.Value.(*history)
+	h.lastUsed = now
+
+	if at, ok := h.seen[key]; ok && now.Sub(at) < m.window {
+		return false
+	}
+	h.seen[key] = now
+	m.trim(h, now)
+	return true
+}
+
+// evictSessions drops expired sessions from the back of the LRU list, then
+// the least recently used ones until there's room for another. Callers hold
+// mu.
+func (m *MemoryHistory) evictSessions(now time.Time) {
+	for e := m.lru.Back(); e != nil; e = m.lru.Back() {
+		h := e.Value.(*history)
+		if now.Sub(h.lastUsed) < m.window && m.lru.Len() < m.maxSessions {
+			return
+		}
+		m.lru.Remove(e)
+		delete(m.sessions, h.id)
+	}
+}
+
+// trim drops expired keys, then the oldest keys while over maxPerSession.
+// Callers hold mu.
+func (m *MemoryHistory) trim(h *history, now time.Time) {
+	for k, at := range h.seen {
+		if now.Sub(at) >= m.window {
+			delete(h.seen, k)
+		}
+	}
+	for len(h.seen) > m.maxPerSession {
+		var oldest string
+		var oldestAt time.Time
+		for k, at := range h.seen {
+			if oldest == "" || at.Before(oldestAt) {
+				oldest, oldestAt = k, at
+			}
+		}
+		delete(h.seen, oldest)
+	}
+}
+
+// Len returns the number of sessions tracked.
+func (m *MemoryHistory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestHistory(t *testing.T, window time.Duration, maxSessions, maxPerSession int) (*MemoryHistory, *time.Time) {
+	t.Helper()
+
+	h, err := NewMemoryHistory(window, maxSessions, maxPerSession)
+	require.NoError(t, err)
+	now := time.Unix(0, 0)
+	h.now = func() time.Time { return now }
+	return h, &now
+}
+
+func TestNewMemoryHistory(t *testing.T) {
+	_, err := NewMemoryHistory(0, 1, 1)
+	assert.Error(t, err)
+
+	_, err = NewMemoryHistory(time.Minute, 0, 1)
+	assert.Error(t, err)
+}
+
+func TestMemoryHistory(t *testing.T) {
+	t.Run("Remembers keys per session for the window", func(t *testing.T) {
+		h, now := newTestHistory(t, time.Minute, 10, 10)
+
+		assert.True(t, h.Claim("a", "fact:x"))
+		assert.False(t, h.Claim("a", "fact:x"))
+
+		assert.True(t, h.Seen("a", "fact:x"))
+		assert.False(t, h.Seen("b", "fact:x"))
+		assert.False(t, h.Seen("a", "fact:y"))
+
+		*now = now.Add(time.Minute)
+		assert.False(t, h.Seen("a", "fact:x"))
+		assert.True(t, h.Claim("a", "fact:x"))
+	})
+
+	t.Run("Bounds keys per session", func(t *testing.T) {
+		h, now := newTestHistory(t, time.Hour, 10, 2)
+
+		for i := 0; i < 3; i++ {
+			*now = now.Add(time.Second)
+			h.Claim("a", fmt.Sprint(i))
+		}
+
+		assert.False(t, h.Seen("a", "0"))
+		assert.True(t, h.Seen("a", "1"))
+		assert.True(t, h.Seen("a", "2"))
+	})
+
+	t.Run("Bounds the number of sessions", func(t *testing.T) {
+		h, now := newTestHistory(t, time.Hour, 2, 10)
+
+		for i := 0; i < 3; i++ {
+			*now = now.Add(time.Second)
+			h.Claim(fmt.Sprint(i), "k")
+		}
+
+		assert.Equal(t, 2, h.Len())
+		assert.False(t, h.Seen("0", "k"))
+		assert.True(t, h.Seen("2", "k"))
+	})
+
+	t.Run("Evicts the least recently used session", func(t *testing.T) {
+		h, now := newTestHistory(t, time.Hour, 2, 10)
+
+		h.Claim("a", "k")
+		*now = now.Add(time.Second)
+		h.Claim("b", "k")
+		*now = now.Add(time.Second)
+		h.Claim("a", "other")
+		*now = now.Add(time.Second)
+		h.Claim("c", "k")
+
+		assert.True(t, h.Seen("a", "k"))
+		assert.False(t, h.Seen("b", "k"))
+	})
+
+	t.Run("Gives a key to only one of concurrent claims", func(t *testing.T) {
+		h, _ := newTestHistory(t, time.Hour, 10, 10)
+
+		var wg sync.WaitGroup
+		var claimed int32
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if h.Claim("a", "k") {
+					atomic.AddInt32(&claimed, 1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), claimed)
+	})
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"net/http"
+)
+
+const (
+	Header     = "X-Session-ID"
+	CookieName = "catserver_session"
+)
+
+const maxSessionIDLength = 128
+
+// Middleware puts the caller's session on the request context for
+// cat.Service. The session comes from the X-Session-ID header or the session
+// cookie. Callers with neither are issued a cookie on the routes with path
+// templates in issueOn, and go without a session elsewhere.
+func Middleware(issueOn ...string) func(http.Handler) http.Handler {
+	issue := make(map[string]bool, len(issueOn))
+	for _, tpl := range issueOn {
+		issue[tpl] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var cookie string
+			if c, err := req.Cookie(CookieName); err == nil {
+				cookie = c.Value
+			}
+			id := req.Header.Get(Header)
+			if id == "" {
+				id = cookie
+			}
+			if len(id) > maxSessionIDLength {
+				id = ""
+			}
+			if id == "" {
+				if !issue[routeTemplate(req)] {
+					next.ServeHTTP(w, req)
+					return
+				}
+				id = newID()
+				cookie = id
+				http.SetCookie(w, &http.Cookie{
+					Name:     CookieName,
+					Value:    id,
+					Path:     "/",
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+
+			ctx := cat.WithSession(req.Context(), id)
+			if issuedID(cookie) {
+				ctx = WithIssued(ctx, cookie)
+			}
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
+
+func routeTemplate(req *http.Request) string {
+	r := mux.CurrentRoute(req)
+	if r == nil {
+		return ""
+	}
+	tpl, _ := r.GetPathTemplate()
+	return tpl
+}
+
+type issuedKey struct{}
+
+// WithIssued returns a copy of ctx for the given server issued session.
+func WithIssued(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, issuedKey{}, id)
+}
+
+// IssuedFromContext returns the session from the caller's session cookie, or
+// "". Unlike the session on cat's context, it is never taken from the
+// X-Session-ID header, which clients set to whatever they like, or from a
+// cookie that isn't shaped like one Middleware issues, so it can own things
+// like favorites.
+func IssuedFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(issuedKey{}).(string)
+	return id
+}
+
+// issuedID reports whether id looks like one from newID: 32 lowercase hex
+// characters.
+func issuedID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	for _, r := range id {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
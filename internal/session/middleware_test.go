@@ -0,0 +1,84 @@
+package session_test
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var got, issued string
+	h := mux.NewRouter()
+	h.Use(session.Middleware("/"))
+	record := func(w http.ResponseWriter, req *http.Request) {
+		got = cat.SessionFromContext(req.Context())
+		issued = session.IssuedFromContext(req.Context())
+	}
+	h.HandleFunc("/", record)
+	h.HandleFunc("/breeds", record)
+
+	t.Run("Uses the session header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(session.Header, "some-session")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, "some-session", got)
+		assert.Empty(t, issued, "the header is never trusted as issued")
+		assert.Empty(t, rr.Result().Cookies())
+	})
+
+	t.Run("Uses the session cookie", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: session.CookieName, Value: "some-session"})
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "some-session", got)
+	})
+
+	t.Run("Issues a cookie to new callers", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		cookies := rr.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, session.CookieName, cookies[0].Name)
+		assert.Equal(t, cookies[0].Value, got)
+		assert.Equal(t, cookies[0].Value, issued)
+		assert.True(t, cookies[0].HttpOnly)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(cookies[0])
+		req.Header.Set(session.Header, "some-session")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, "some-session", got)
+		assert.Equal(t, cookies[0].Value, issued)
+	})
+
+	t.Run("Doesn't treat a made up cookie as issued", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: session.CookieName, Value: "alice"})
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "alice", got)
+		assert.Empty(t, issued)
+	})
+
+	t.Run("Issues no session on other routes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/breeds", nil)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		assert.Empty(t, got)
+		assert.Empty(t, rr.Result().Cookies())
+
+		req.AddCookie(&http.Cookie{Name: session.CookieName, Value: "some-session"})
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, "some-session", got)
+	})
+}
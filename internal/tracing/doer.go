@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+type tracingDoer struct {
+	upstream string
+	next     cat.Doer
+}
+
+// Doer wraps next so that every call gets a client span and carries a
+// traceparent header to the upstream.
+func Doer(upstream string, next cat.Doer) cat.Doer {
+	return &tracingDoer{upstream: upstream, next: next}
+}
+
+func (d *tracingDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx, span := otel.Tracer(instrumentationName).Start(req.Context(), "Doer.Do "+d.upstream,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("upstream", d.upstream),
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.String()),
+		),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	res, err := d.next.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return res, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+	if res.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, res.Status)
+	}
+	return res, nil
+}
@@ -0,0 +1,70 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/matthewjamesboyle/catserver/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"testing"
+)
+
+func TestDoer(t *testing.T) {
+	t.Run("Creates a client span and injects traceparent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		exp := newRecorder(t)
+
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://some-url", nil)
+		require.NoError(t, err)
+
+		var sent *http.Request
+		md := mockcat.NewMockDoer(ctrl)
+		md.EXPECT().Do(gomock.Any()).DoAndReturn(func(r *http.Request) (*http.Response, error) {
+			sent = r
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		})
+
+		_, err = tracing.Doer("fact", md).Do(req)
+		require.NoError(t, err)
+		parent.End()
+
+		spans := exp.GetSpans()
+		require.Len(t, spans, 2)
+		client := spans[0]
+		assert.Equal(t, "Doer.Do fact", client.Name)
+		assert.Equal(t, trace.SpanKindClient, client.SpanKind)
+		assert.Equal(t, parent.SpanContext().TraceID(), client.SpanContext.TraceID())
+
+		require.NotNil(t, sent)
+		assert.Contains(t, sent.Header.Get("traceparent"), client.SpanContext.TraceID().String())
+		assert.Contains(t, sent.Header.Get("traceparent"), client.SpanContext.SpanID().String())
+		assert.Empty(t, req.Header.Get("traceparent"), "the caller's request must not be modified")
+	})
+
+	t.Run("Marks the span as failed given the call fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		exp := newRecorder(t)
+
+		req, err := http.NewRequest(http.MethodGet, "http://some-url", nil)
+		require.NoError(t, err)
+
+		md := mockcat.NewMockDoer(ctrl)
+		md.EXPECT().Do(gomock.Any()).Return(nil, errors.New("some-error"))
+
+		_, err = tracing.Doer("image", md).Do(req)
+		assert.Error(t, err)
+
+		spans := exp.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+	})
+}
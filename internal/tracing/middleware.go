@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/httpstatus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// Middleware starts a server span for each request, continuing any trace
+// passed in a traceparent header. Install it with mux.Router.Use so spans are
+// named after the matched route.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		route := req.URL.Path
+		if r := mux.CurrentRoute(req); r != nil {
+			if tpl, err := r.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		ctx, span := otel.Tracer(instrumentationName).Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(req.Method),
+				semconv.HTTPRoute(route),
+			),
+		)
+		defer span.End()
+
+		rec := httpstatus.NewRecorder(w)
+		next.ServeHTTP(rec, req.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(rec.Code))
+		if rec.Code >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.Code))
+		}
+	})
+}
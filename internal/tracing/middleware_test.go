@@ -0,0 +1,54 @@
+package tracing_test
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	exp := newRecorder(t)
+
+	var inner trace.SpanContext
+	r := mux.NewRouter()
+	r.Use(tracing.Middleware)
+	r.HandleFunc("/c/{id}", func(w http.ResponseWriter, req *http.Request) {
+		inner = trace.SpanContextFromContext(req.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/c/abc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a50efa0ad19a2a6a-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "GET /c/{id}", s.Name)
+	assert.Equal(t, trace.SpanKindServer, s.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a50efa0ad19a2a6a", s.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", s.Parent.SpanID().String())
+	assert.Equal(t, s.SpanContext.SpanID(), inner.SpanID())
+}
+
+func TestMiddleware_Flush(t *testing.T) {
+	newRecorder(t)
+
+	r := mux.NewRouter()
+	r.Use(tracing.Middleware)
+	r.HandleFunc("/stream", func(w http.ResponseWriter, _ *http.Request) {
+		f, ok := w.(http.Flusher)
+		require.True(t, ok)
+		f.Flush()
+	})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+	assert.True(t, rr.Flushed)
+}
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"os"
+)
+
+const instrumentationName = "github.com/matthewjamesboyle/catserver/internal/tracing"
+
+// Exporters understood by NewExporter.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// NewExporter builds a span exporter by name. The OTLP exporter is
+// configured with the standard OTEL_EXPORTER_OTLP_* environment variables.
+// It returns a nil exporter for ExporterNone.
+func NewExporter(ctx context.Context, name string) (sdktrace.SpanExporter, error) {
+	switch name {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		return otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", name)
+	}
+}
+
+// NewProvider creates a TracerProvider that batches spans to exp, and installs
+// it and the W3C trace context propagator as the otel globals. A nil exporter
+// still records spans so trace IDs propagate, but exports nothing.
+func NewProvider(serviceName string, exp sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	}
+	if exp != nil {
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return tp
+}
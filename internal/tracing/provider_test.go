@@ -0,0 +1,31 @@
+package tracing_test
+
+import (
+	"context"
+	"github.com/matthewjamesboyle/catserver/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewExporter(t *testing.T) {
+	t.Run("Returns a nil exporter for none", func(t *testing.T) {
+		exp, err := tracing.NewExporter(context.Background(), tracing.ExporterNone)
+
+		assert.NoError(t, err)
+		assert.Nil(t, exp)
+	})
+
+	t.Run("Returns a stdout exporter", func(t *testing.T) {
+		exp, err := tracing.NewExporter(context.Background(), tracing.ExporterStdout)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, exp)
+	})
+
+	t.Run("Returns an error given an unknown exporter", func(t *testing.T) {
+		exp, err := tracing.NewExporter(context.Background(), "some-exporter")
+
+		assert.Error(t, err)
+		assert.Nil(t, exp)
+	})
+}
@@ -0,0 +1,25 @@
+package tracing_test
+
+import (
+	"context"
+	"github.com/matthewjamesboyle/catserver/internal/tracing"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+// newRecorder installs a provider that exports synchronously to memory.
+func newRecorder(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exp := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	tp := tracing.NewProvider("catserver-test", nil)
+	tp.RegisterSpanProcessor(sdktrace.NewSimpleSpanProcessor(exp))
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+		otel.SetTracerProvider(prev)
+	})
+	return exp
+}
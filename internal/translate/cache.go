@@ -0,0 +1,124 @@
+package translate
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"sync"
+)
+
+type cacheKey struct {
+	lang string
+	text cat.Fact
+}
+
+type cached struct {
+	key  cacheKey
+	fact cat.Fact
+	err  error
+}
+
+// maxUnsupported is how many languages the Cache remembers are unsupported.
+const maxUnsupported = 1000
+
+// Cache is a Translator that remembers up to capacity of next's answers,
+// dropping the least recently used first. Translations and ErrNoTranslation
+// answers are cached by language and fact. ErrUnsupportedLanguage answers
+// are cached by language alone, apart from the translations, so made-up
+// languages neither cost a call per fact nor push real translations out.
+// Other errors are not cached, so they are retried.
+type Cache struct {
+	next     cat.Translator
+	capacity int
+	onLookup func(hit bool)
+
+	mu    sync.Mutex
+	order *list.List
+	items map[cacheKey]*list.Element
+	// unsupported is languages, most recently used first, with their
+	// elements by language in unsupportedIdx.
+	unsupported    *list.List
+	unsupportedIdx map[string]*list.Element
+}
+
+// NewCache wraps next. onLookup, if not nil, is told about every hit and
+// miss.
+func NewCache(next cat.Translator, capacity int, onLookup func(hit bool)) (*Cache, error) {
+	if next == nil {
+		return nil, cat.ErrNilParam{Parameter: "Translator"}
+	}
+	if capacity < 1 {
+		return nil, errors.New("capacity must be at least 1")
+	}
+	if onLookup == nil {
+		onLookup = func(bool) {}
+	}
+	return &Cache{
+		next:     next,
+		capacity: capacity,
+		onLookup: onLookup,
+		order:    list.New(),
+		items:    make(map[cacheKey]*list.Element),
+
+		unsupported:    list.New(),
+		unsupportedIdx: make(map[string]*list.Element),
+	}, nil
+}
+
+func (c *Cache) Translate(ctx context.Context, text cat.Fact, lang string) (cat.Fact, error) {
+	k := cacheKey{lang: lang, text: text}
+
+	c.mu.Lock()
+	if el, ok := c.unsupportedIdx[lang]; ok {
+		c.unsupported.MoveToFront(el)
+		c.mu.Unlock()
+		c.onLookup(true)
+		return "", cat.ErrUnsupportedLanguage
+	}
+	if el, ok := c.items[k]; ok {
+		c.order.MoveToFront(el)
+		v := el.Value.(*cached)
+		c.mu.Unlock()
+		c.onLookup(true)
+		return v.fact, v.err
+	}
+	c.mu.Unlock()
+	c.onLookup(false)
+
+	f, err := c.next.Translate(ctx, text, lang)
+	if err != nil && !errors.Is(err, cat.ErrUnsupportedLanguage) && !errors.Is(err, cat.ErrNoTranslation) {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if errors.Is(err, cat.ErrUnsupportedLanguage) {
+		if _, ok := c.unsupportedIdx[lang]; !ok {
+			c.unsupportedIdx[lang] = c.unsupported.PushFront(lang)
+			if c.unsupported.Len() > maxUnsupported {
+				oldest := c.unsupported.Back()
+				c.unsupported.Remove(oldest)
+				delete(c.unsupportedIdx, oldest.Value.(string))
+			}
+		}
+		return f, err
+	}
+	if el, ok := c.items[k]; ok {
+		c.order.MoveToFront(el)
+		return f, err
+	}
+	c.items[k] = c.order.PushFront(&cached{key: k, fact: f, err: err})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cached).key)
+	}
+	return f, err
+}
+
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,110 @@
+package translate_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/matthewjamesboyle/catserver/internal/translate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewCache(t *testing.T) {
+	_, err := translate.NewCache(nil, 1, nil)
+	assert.Error(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	_, err = translate.NewCache(mockcat.NewMockTranslator(ctrl), 0, nil)
+	assert.Error(t, err)
+}
+
+func TestCache_Translate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Caches translations and unsupported languages", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		next := mockcat.NewMockTranslator(ctrl)
+		next.EXPECT().Translate(gomock.Any(), cat.Fact("Cats purr."), "de").Return(cat.Fact("Katzen schnurren."), nil).Times(1)
+		next.EXPECT().Translate(gomock.Any(), cat.Fact("Cats purr."), "xx").Return(cat.Fact(""), cat.ErrUnsupportedLanguage).Times(1)
+
+		var hits, misses int
+		c, err := translate.NewCache(next, 10, func(hit bool) {
+			if hit {
+				hits++
+			} else {
+				misses++
+			}
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			f, err := c.Translate(ctx, "Cats purr.", "de")
+			require.NoError(t, err)
+			assert.Equal(t, cat.Fact("Katzen schnurren."), f)
+
+			_, err = c.Translate(ctx, "Cats purr.", "xx")
+			assert.ErrorIs(t, err, cat.ErrUnsupportedLanguage)
+		}
+		assert.Equal(t, 2, hits)
+		assert.Equal(t, 2, misses)
+	})
+
+	t.Run("Caches unsupported languages for every fact, apart from translations", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		next := mockcat.NewMockTranslator(ctrl)
+		next.EXPECT().Translate(gomock.Any(), gomock.Any(), "xx").Return(cat.Fact(""), cat.ErrUnsupportedLanguage).Times(1)
+
+		c, err := translate.NewCache(next, 10, nil)
+		require.NoError(t, err)
+
+		for _, f := range []cat.Fact{"Cats purr.", "Cats nap.", "Cats climb."} {
+			_, err := c.Translate(ctx, f, "xx")
+			assert.ErrorIs(t, err, cat.ErrUnsupportedLanguage)
+		}
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("Retries other errors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		next := mockcat.NewMockTranslator(ctrl)
+		next.EXPECT().Translate(gomock.Any(), gomock.Any(), gomock.Any()).Return(cat.Fact(""), errors.New("boom")).Times(2)
+
+		c, err := translate.NewCache(next, 10, nil)
+		require.NoError(t, err)
+
+		_, err = c.Translate(ctx, "Cats purr.", "de")
+		assert.Error(t, err)
+		_, err = c.Translate(ctx, "Cats purr.", "de")
+		assert.Error(t, err)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("Evicts the least recently used", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		next := mockcat.NewMockTranslator(ctrl)
+		next.EXPECT().Translate(gomock.Any(), gomock.Any(), "de").Return(cat.Fact("a"), nil).Times(1)
+		next.EXPECT().Translate(gomock.Any(), gomock.Any(), "fr").Return(cat.Fact("b"), nil).Times(2)
+		next.EXPECT().Translate(gomock.Any(), gomock.Any(), "es").Return(cat.Fact("c"), nil).Times(2)
+
+		c, err := translate.NewCache(next, 2, nil)
+		require.NoError(t, err)
+
+		for _, lang := range []string{"de", "fr", "de", "es", "de", "fr", "es"} {
+			_, err := c.Translate(ctx, "x", lang)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, c.Len())
+	})
+}
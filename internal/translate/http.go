@@ -0,0 +1,83 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HTTPTranslator calls a LibreTranslate compatible translation service.
+type HTTPTranslator struct {
+	hc      cat.Doer
+	baseURL string
+	apiKey  string
+}
+
+func NewHTTPTranslator(hc cat.Doer, baseURL, apiKey string) (*HTTPTranslator, error) {
+	if hc == nil {
+		return nil, cat.ErrNilParam{Parameter: "hc"}
+	}
+	if baseURL == "" {
+		return nil, cat.ErrNilParam{Parameter: "baseURL"}
+	}
+	return &HTTPTranslator{hc: hc, baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey}, nil
+}
+
+type translateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type translateResponse struct {
+	TranslatedText string `json:"translatedText"`
+}
+
+func (t *HTTPTranslator) Translate(ctx context.Context, text cat.Fact, lang string) (cat.Fact, error) {
+	body, err := json.Marshal(translateRequest{
+		Q:      string(text),
+		Source: cat.SourceLanguage,
+		Target: lang,
+		Format: "text",
+		APIKey: t.apiKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/translate", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.hc.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling translation service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusBadRequest:
+		// LibreTranslate answers 400 for languages it doesn't have.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return "", cat.ErrUnsupportedLanguage
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("translation service returned %d", resp.StatusCode)
+	}
+
+	var tr translateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return "", fmt.Errorf("unmarshall Response: %w", err)
+	}
+	if tr.TranslatedText == "" {
+		return "", cat.ErrNoTranslation
+	}
+	return cat.Fact(tr.TranslatedText), nil
+}
@@ -0,0 +1,62 @@
+package translate_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/translate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubTranslator is a local stand-in for a LibreTranslate server.
+func stubTranslator(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/translate", req.URL.Path)
+		assert.Equal(t, http.MethodPost, req.Method)
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.Equal(t, "en", body["source"])
+		assert.Equal(t, "secret", body["api_key"])
+
+		switch body["target"] {
+		case "es":
+			_ = json.NewEncoder(w).Encode(map[string]string{"translatedText": "[es] " + body["q"]})
+		case "xx":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "xx is not supported"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+}
+
+func TestNewHTTPTranslator(t *testing.T) {
+	_, err := translate.NewHTTPTranslator(nil, "http://localhost", "")
+	assert.Error(t, err)
+	_, err = translate.NewHTTPTranslator(http.DefaultClient, "", "")
+	assert.Error(t, err)
+}
+
+func TestHTTPTranslator_Translate(t *testing.T) {
+	srv := stubTranslator(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	tr, err := translate.NewHTTPTranslator(srv.Client(), srv.URL+"/", "secret")
+	require.NoError(t, err)
+
+	f, err := tr.Translate(ctx, "Cats purr.", "es")
+	require.NoError(t, err)
+	assert.Equal(t, cat.Fact("[es] Cats purr."), f)
+
+	_, err = tr.Translate(ctx, "Cats purr.", "xx")
+	assert.ErrorIs(t, err, cat.ErrUnsupportedLanguage)
+
+	_, err = tr.Translate(ctx, "Cats purr.", "fr")
+	assert.EqualError(t, err, "translation service returned 500")
+}
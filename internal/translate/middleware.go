@@ -0,0 +1,57 @@
+package translate
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"golang.org/x/text/language"
+	"net/http"
+)
+
+// Middleware stores the languages from the request's Accept-Language header
+// on its context for cat.Service to translate into.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+
+		if langs := Languages(req.Header.Get("Accept-Language")); len(langs) > 0 {
+			req = req.WithContext(cat.WithLanguages(req.Context(), langs...))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+var mul = language.Make("mul")
+
+// MaxLanguages is the most tags Languages returns, as each may cost a call
+// to the translation service.
+const MaxLanguages = 5
+
+// Languages parses an Accept-Language header into language tags, most
+// preferred first, keeping at most MaxLanguages. Each regional tag is
+// followed by its base language, so "pt-BR" falls back to "pt" before the
+// next preference.
+func Languages(header string) []string {
+	if header == "" {
+		return nil
+	}
+	tags, q, _ := language.ParseAcceptLanguage(header)
+
+	var out []string
+	seen := make(map[string]bool)
+	add := func(s string) {
+		if !seen[s] && len(out) < MaxLanguages {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	for i, t := range tags {
+		// "*" parses as "mul"; it says nothing we can act on.
+		if q[i] <= 0 || t == language.Und || t == mul {
+			continue
+		}
+		add(t.String())
+		if base, conf := t.Base(); conf != language.No {
+			add(base.String())
+		}
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package translate_test
+
+import (
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/translate"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLanguages(t *testing.T) {
+	assert.Equal(t, []string{"pt-BR", "pt", "de", "en"}, translate.Languages("en;q=0.1, pt-BR, de;q=0.8, fr;q=0"))
+	assert.Equal(t, []string{"de"}, translate.Languages("de, *;q=0.5"))
+	assert.Nil(t, translate.Languages(""))
+	assert.Equal(t, []string{"de-DE", "de", "fr-FR", "fr", "es-ES"},
+		translate.Languages("de-DE, fr-FR, es-ES, it-IT, nl-NL"), "at most MaxLanguages")
+}
+
+func TestMiddleware(t *testing.T) {
+	var got []string
+	h := translate.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = cat.LanguagesFromContext(req.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "es-MX,es;q=0.9")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, []string{"es-MX", "es"}, got)
+	assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+}
@@ -0,0 +1,120 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Book is the phrasebook for one language. Phrases are whole facts;
+// Words are used to translate facts word by word when every word is known.
+type Book struct {
+	Phrases map[string]string `json:"phrases"`
+	Words   map[string]string `json:"words"`
+}
+
+// Phrasebook is an offline Translator backed by hand-written translations,
+// keyed by language tag.
+type Phrasebook struct {
+	books map[string]Book
+}
+
+func NewPhrasebook(books map[string]Book) *Phrasebook {
+	p := &Phrasebook{books: make(map[string]Book, len(books))}
+	for lang, b := range books {
+		nb := Book{Phrases: make(map[string]string, len(b.Phrases)), Words: make(map[string]string, len(b.Words))}
+		for k, v := range b.Phrases {
+			nb.Phrases[phraseKey(k)] = v
+		}
+		for k, v := range b.Words {
+			nb.Words[strings.ToLower(k)] = v
+		}
+		p.books[strings.ToLower(lang)] = nb
+	}
+	return p
+}
+
+// LoadPhrasebook reads a phrasebook from a JSON file of the form
+// {"de": {"phrases": {"Cats purr.": "Katzen schnurren."}, "words": {"cat": "Katze"}}}.
+func LoadPhrasebook(path string) (*Phrasebook, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading phrasebook: %w", err)
+	}
+	var books map[string]Book
+	if err := json.Unmarshal(b, &books); err != nil {
+		return nil, fmt.Errorf("parsing phrasebook: %w", err)
+	}
+	return NewPhrasebook(books), nil
+}
+
+func (p *Phrasebook) Translate(_ context.Context, text cat.Fact, lang string) (cat.Fact, error) {
+	b, ok := p.books[strings.ToLower(lang)]
+	if !ok {
+		return "", cat.ErrUnsupportedLanguage
+	}
+	if t, ok := b.Phrases[phraseKey(string(text))]; ok {
+		return cat.Fact(t), nil
+	}
+	if t, ok := b.wordByWord(string(text)); ok {
+		return cat.Fact(t), nil
+	}
+	return "", cat.ErrNoTranslation
+}
+
+// wordByWord swaps each word for its dictionary entry, keeping punctuation
+// and capitalised first letters. A half-translated fact is worse than the
+// original, so it gives up on the first unknown word.
+func (b Book) wordByWord(text string) (string, bool) {
+	if len(b.Words) == 0 {
+		return "", false
+	}
+
+	var out strings.Builder
+	var word strings.Builder
+	flush := func() bool {
+		if word.Len() == 0 {
+			return true
+		}
+		w := word.String()
+		word.Reset()
+		t, ok := b.Words[strings.ToLower(w)]
+		if !ok {
+			return false
+		}
+		if r, _ := utf8.DecodeRuneInString(w); unicode.IsUpper(r) {
+			t = capitalise(t)
+		}
+		out.WriteString(t)
+		return true
+	}
+
+	for _, r := range text {
+		if unicode.IsLetter(r) || r == '\'' {
+			word.WriteRune(r)
+			continue
+		}
+		if !flush() {
+			return "", false
+		}
+		out.WriteRune(r)
+	}
+	if !flush() {
+		return "", false
+	}
+	return out.String(), true
+}
+
+func capitalise(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+func phraseKey(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,50 @@
+package translate_test
+
+import (
+	"context"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/translate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPhrasebook_Translate(t *testing.T) {
+	ctx := context.Background()
+	p := translate.NewPhrasebook(map[string]translate.Book{
+		"DE": {
+			Phrases: map[string]string{"Cats purr when happy.": "Katzen schnurren, wenn sie glücklich sind."},
+			Words:   map[string]string{"cats": "Katzen", "sleep": "schlafen", "a": "viel", "lot": ""},
+		},
+	})
+
+	f, err := p.Translate(ctx, "  cats purr   when happy. ", "de")
+	require.NoError(t, err)
+	assert.Equal(t, cat.Fact("Katzen schnurren, wenn sie glücklich sind."), f)
+
+	f, err = p.Translate(ctx, "Cats sleep!", "de")
+	require.NoError(t, err)
+	assert.Equal(t, cat.Fact("Katzen schlafen!"), f)
+
+	_, err = p.Translate(ctx, "Cats sleep on mats.", "de")
+	assert.ErrorIs(t, err, cat.ErrNoTranslation)
+
+	_, err = p.Translate(ctx, "Cats sleep!", "fr")
+	assert.ErrorIs(t, err, cat.ErrUnsupportedLanguage)
+}
+
+func TestLoadPhrasebook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "phrasebook.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"fr": {"phrases": {"Cats purr.": "Les chats ronronnent."}}}`), 0600))
+
+	p, err := translate.LoadPhrasebook(path)
+	require.NoError(t, err)
+	f, err := p.Translate(context.Background(), "Cats purr.", "fr")
+	require.NoError(t, err)
+	assert.Equal(t, cat.Fact("Les chats ronronnent."), f)
+
+	_, err = translate.LoadPhrasebook(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package transport
+
+import (
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"net/http"
+)
+
+type BreedsHandler struct {
+	l cat.BreedLister
+}
+
+func NewBreedsHandler(l cat.BreedLister) (*BreedsHandler, error) {
+	if l == nil {
+		return nil, errors.New("nil breed lister")
+	}
+	return &BreedsHandler{l: l}, nil
+}
+
+// List returns the breeds that can be passed as ?breed= to /.
+func (h BreedsHandler) List(w http.ResponseWriter, req *http.Request) {
+	breeds, err := h.l.ListBreeds(req.Context())
+	if err != nil {
+		logging.FromContext(req.Context()).Error("listing breeds", "error", err)
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	if breeds == nil {
+		breeds = []cat.Breed{}
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	writeJSON(w, http.StatusOK, breeds)
+}
@@ -0,0 +1,57 @@
+package transport_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/matthewjamesboyle/catserver/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewBreedsHandler(t *testing.T) {
+	h, err := transport.NewBreedsHandler(nil)
+
+	assert.Nil(t, h)
+	assert.Error(t, err)
+}
+
+func TestBreedsHandler_List(t *testing.T) {
+	t.Run("Returns the breeds", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		l := mockcat.NewMockBreedLister(ctrl)
+		l.EXPECT().ListBreeds(gomock.Any()).Return([]cat.Breed{{ID: "beng", Name: "Bengal"}}, nil)
+		h, err := transport.NewBreedsHandler(l)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		h.List(rr, httptest.NewRequest(http.MethodGet, "/breeds", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got []cat.Breed
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+		assert.Equal(t, []cat.Breed{{ID: "beng", Name: "Bengal"}}, got)
+	})
+
+	t.Run("Returns a 502 given the upstream fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		l := mockcat.NewMockBreedLister(ctrl)
+		l.EXPECT().ListBreeds(gomock.Any()).Return(nil, errors.New("some-error"))
+		h, err := transport.NewBreedsHandler(l)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		h.List(rr, httptest.NewRequest(http.MethodGet, "/breeds", nil))
+
+		assert.Equal(t, http.StatusBadGateway, rr.Code)
+	})
+}
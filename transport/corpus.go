@@ -0,0 +1,28 @@
+package transport
+
+import (
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/corpus"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"net/http"
+)
+
+type CorpusHandler struct {
+	c *corpus.Corpus
+}
+
+func NewCorpusHandler(c *corpus.Corpus) (*CorpusHandler, error) {
+	if c == nil {
+		return nil, errors.New("nil corpus")
+	}
+	return &CorpusHandler{c: c}, nil
+}
+
+// Export streams the whole corpus as JSON lines.
+func (h CorpusHandler) Export(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="facts.jsonl"`)
+	if err := h.c.Export(w); err != nil {
+		logging.FromContext(req.Context()).Error("exporting corpus", "error", err)
+	}
+}
@@ -0,0 +1,39 @@
+package transport_test
+
+import (
+	"encoding/json"
+	"github.com/matthewjamesboyle/catserver/internal/corpus"
+	"github.com/matthewjamesboyle/catserver/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewCorpusHandler(t *testing.T) {
+	h, err := transport.NewCorpusHandler(nil)
+
+	assert.Nil(t, h)
+	assert.Error(t, err)
+}
+
+func TestCorpusHandler_Export(t *testing.T) {
+	c, err := corpus.NewCorpus("")
+	require.NoError(t, err)
+	_, err = c.Add("some-fact", "catfact", time.Now())
+	require.NoError(t, err)
+	h, err := transport.NewCorpusHandler(c)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	h.Export(rr, httptest.NewRequest(http.MethodGet, "/facts/export", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	var e corpus.Entry
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&e))
+	assert.Equal(t, "some-fact", e.Text)
+	assert.Equal(t, "catfact", e.Provider)
+}
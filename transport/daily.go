@@ -0,0 +1,68 @@
+package transport
+
+import (
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/daily"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type DailyHandler struct {
+	d *daily.Daily
+}
+
+func NewDailyHandler(d *daily.Daily) (*DailyHandler, error) {
+	if d == nil {
+		return nil, errors.New("nil daily")
+	}
+	return &DailyHandler{d: d}, nil
+}
+
+type dailyResult struct {
+	Date   string        `json:"date"`
+	Result cat.CatResult `json:"result"`
+}
+
+func (h DailyHandler) Get(w http.ResponseWriter, req *http.Request) {
+	now := h.d.Now()
+
+	date := req.URL.Query().Get("date")
+	var c cat.CatResult
+	var err error
+	if date == "" {
+		date, c, err = h.d.Today(req.Context())
+	} else {
+		c, err = h.d.Get(req.Context(), date)
+	}
+
+	var pe *time.ParseError
+	switch {
+	case errors.As(err, &pe), errors.Is(err, daily.ErrFutureDate):
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	case errors.Is(err, daily.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case writeQuotaProblem(w, err):
+		return
+	case err != nil:
+		logging.FromContext(req.Context()).Error("getting daily result", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Today's result changes at local midnight; past days never change.
+	expires := h.d.NextMidnight(now)
+	if date != now.In(expires.Location()).Format(daily.DateLayout) {
+		expires = now.Add(365 * 24 * time.Hour)
+	}
+	maxAge := int(expires.Sub(now).Seconds())
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+	w.Header().Set("Expires", expires.UTC().Format(http.TimeFormat))
+	w.Header().Set("Vary", "Accept-Language")
+
+	writeJSON(w, http.StatusOK, dailyResult{Date: date, Result: c})
+}
@@ -0,0 +1,79 @@
+package transport_test
+
+import (
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/daily"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/matthewjamesboyle/catserver/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewDailyHandler(t *testing.T) {
+	h, err := transport.NewDailyHandler(nil)
+
+	assert.Nil(t, h)
+	assert.Error(t, err)
+}
+
+func TestDailyHandler_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := mockcat.NewMockServicer(ctrl)
+	s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{Fact: "some-fact"}, nil).Times(1)
+
+	d, err := daily.NewDaily(s, time.UTC, "")
+	require.NoError(t, err)
+	h, err := transport.NewDailyHandler(d)
+	require.NoError(t, err)
+
+	t.Run("Returns today's result cached until midnight", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.Get(rr, httptest.NewRequest(http.MethodGet, "/daily", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var res struct {
+			Date   string        `json:"date"`
+			Result cat.CatResult `json:"result"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Equal(t, time.Now().UTC().Format(daily.DateLayout), res.Date)
+		assert.Equal(t, cat.Fact("some-fact"), res.Result.Fact)
+
+		expires, err := http.ParseTime(rr.Header().Get("Expires"))
+		require.NoError(t, err)
+		assert.Equal(t, d.NextMidnight(time.Now()).UTC(), expires)
+		assert.Contains(t, rr.Header().Get("Cache-Control"), "max-age=")
+	})
+
+	t.Run("Returns today's result given today's date", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.Get(rr, httptest.NewRequest(http.MethodGet, "/daily?date="+time.Now().UTC().Format(daily.DateLayout), nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Returns 404 for a day with no archived result", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.Get(rr, httptest.NewRequest(http.MethodGet, "/daily?date=2000-01-01", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Returns 400 for an invalid or future date", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.Get(rr, httptest.NewRequest(http.MethodGet, "/daily?date=tomorrow", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = httptest.NewRecorder()
+		h.Get(rr, httptest.NewRequest(http.MethodGet, "/daily?date=2999-01-01", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
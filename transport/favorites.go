@@ -0,0 +1,143 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/favorite"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"github.com/matthewjamesboyle/catserver/internal/problem"
+	"github.com/matthewjamesboyle/catserver/internal/session"
+	"net/http"
+	"strconv"
+)
+
+type FavoritesHandler struct {
+	s favorite.FavoriteStore
+}
+
+func NewFavoritesHandler(s favorite.FavoriteStore) (*FavoritesHandler, error) {
+	if s == nil {
+		return nil, errors.New("nil favorite store")
+	}
+	return &FavoritesHandler{s: s}, nil
+}
+
+type favoritesPage struct {
+	Favorites  []favorite.Favorite `json:"favorites"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// owner scopes favorites to the authenticated caller or, without API keys,
+// to the session cookie the caller was issued. The X-Session-ID header is
+// never used, as anyone could send someone else's.
+func owner(req *http.Request) (string, bool) {
+	if id, ok := auth.IdentityFromContext(req.Context()); ok {
+		if id.Owner != "" {
+			return id.Owner, true
+		}
+		return id.KeyID, id.KeyID != ""
+	}
+	if s := session.IssuedFromContext(req.Context()); s != "" {
+		return "session:" + s, true
+	}
+	return "", false
+}
+
+func writeNoOwnerProblem(w http.ResponseWriter) {
+	problem.Write(w, problem.Problem{
+		Type:   "/problems/unauthenticated",
+		Status: http.StatusUnauthorized,
+		Detail: "favorites need an API key or a session",
+	})
+}
+
+func (h FavoritesHandler) Create(w http.ResponseWriter, req *http.Request) {
+	o, ok := owner(req)
+	if !ok {
+		writeNoOwnerProblem(w)
+		return
+	}
+
+	var c cat.CatResult
+	if err := json.NewDecoder(req.Body).Decode(&c); err != nil || c.Fact == "" || c.ImageURL == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f, err := h.s.Save(req.Context(), o, c)
+	if err != nil {
+		logging.FromContext(req.Context()).Error("saving favorite", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, f)
+}
+
+func (h FavoritesHandler) List(w http.ResponseWriter, req *http.Request) {
+	o, ok := owner(req)
+	if !ok {
+		writeNoOwnerProblem(w)
+		return
+	}
+
+	var limit int
+	if l := req.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	favs, next, err := h.s.List(req.Context(), o, req.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		logging.FromContext(req.Context()).Error("listing favorites", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if favs == nil {
+		favs = []favorite.Favorite{}
+	}
+	writeJSON(w, http.StatusOK, favoritesPage{Favorites: favs, NextCursor: next})
+}
+
+func (h FavoritesHandler) Delete(w http.ResponseWriter, req *http.Request) {
+	o, ok := owner(req)
+	if !ok {
+		writeNoOwnerProblem(w)
+		return
+	}
+
+	err := h.s.Delete(req.Context(), o, mux.Vars(req)["id"])
+	switch {
+	case errors.Is(err, favorite.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case err != nil:
+		logging.FromContext(req.Context()).Error("deleting favorite", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Register adds the favorites routes to r.
+func (h FavoritesHandler) Register(r *mux.Router) {
+	r.HandleFunc("/favorites", h.Create).Methods(http.MethodPost)
+	r.HandleFunc("/favorites", h.List).Methods(http.MethodGet)
+	r.HandleFunc("/favorites/{id}", h.Delete).Methods(http.MethodDelete)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	res, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(res)
+}
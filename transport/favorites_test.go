@@ -0,0 +1,156 @@
+package transport_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/favorite"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockfavorite"
+	"github.com/matthewjamesboyle/catserver/internal/session"
+	"github.com/matthewjamesboyle/catserver/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func favoritesRouter(t *testing.T, s favorite.FavoriteStore) *mux.Router {
+	t.Helper()
+
+	h, err := transport.NewFavoritesHandler(s)
+	require.NoError(t, err)
+	r := mux.NewRouter()
+	h.Register(r)
+	return r
+}
+
+func asAlice(req *http.Request) *http.Request {
+	return req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{KeyID: "k1", Owner: "alice"}))
+}
+
+func TestNewFavoritesHandler(t *testing.T) {
+	h, err := transport.NewFavoritesHandler(nil)
+
+	assert.Nil(t, h)
+	assert.Error(t, err)
+}
+
+func TestFavoritesHandler(t *testing.T) {
+	someResult := cat.CatResult{ImageURL: "http://someurl", Fact: "some-fact"}
+
+	t.Run("Returns a 401 problem without a caller identity or session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rr := httptest.NewRecorder()
+		favoritesRouter(t, mockfavorite.NewMockFavoriteStore(ctrl)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/favorites", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("Keys favorites on the issued session without a caller identity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockfavorite.NewMockFavoriteStore(ctrl)
+		s.EXPECT().List(gomock.Any(), "session:some-session", "", 0).Return(nil, "", nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/favorites", nil)
+		req = req.WithContext(session.WithIssued(req.Context(), "some-session"))
+		rr := httptest.NewRecorder()
+		favoritesRouter(t, s).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Returns a 401 given only a client supplied session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		req := httptest.NewRequest(http.MethodGet, "/favorites", nil)
+		req = req.WithContext(cat.WithSession(req.Context(), "some-session"))
+		rr := httptest.NewRecorder()
+		favoritesRouter(t, mockfavorite.NewMockFavoriteStore(ctrl)).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Saves a favorite for the caller", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockfavorite.NewMockFavoriteStore(ctrl)
+		s.EXPECT().Save(gomock.Any(), "alice", someResult).Return(favorite.Favorite{ID: "some-id", Result: someResult}, nil)
+
+		b, err := json.Marshal(someResult)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		favoritesRouter(t, s).ServeHTTP(rr, asAlice(httptest.NewRequest(http.MethodPost, "/favorites", bytes.NewBuffer(b))))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var f favorite.Favorite
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&f))
+		assert.Equal(t, "some-id", f.ID)
+	})
+
+	t.Run("Returns 400 given an invalid body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rr := httptest.NewRecorder()
+		favoritesRouter(t, mockfavorite.NewMockFavoriteStore(ctrl)).ServeHTTP(rr, asAlice(httptest.NewRequest(http.MethodPost, "/favorites", bytes.NewBufferString("{}"))))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Lists a page of favorites", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockfavorite.NewMockFavoriteStore(ctrl)
+		s.EXPECT().List(gomock.Any(), "alice", "some-cursor", 2).Return([]favorite.Favorite{{ID: "a"}, {ID: "b"}}, "b", nil)
+
+		rr := httptest.NewRecorder()
+		favoritesRouter(t, s).ServeHTTP(rr, asAlice(httptest.NewRequest(http.MethodGet, "/favorites?cursor=some-cursor&limit=2", nil)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var res struct {
+			Favorites  []favorite.Favorite `json:"favorites"`
+			NextCursor string              `json:"next_cursor"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+		assert.Len(t, res.Favorites, 2)
+		assert.Equal(t, "b", res.NextCursor)
+	})
+
+	t.Run("Deletes a favorite", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockfavorite.NewMockFavoriteStore(ctrl)
+		s.EXPECT().Delete(gomock.Any(), "alice", "some-id").Return(nil)
+
+		rr := httptest.NewRecorder()
+		favoritesRouter(t, s).ServeHTTP(rr, asAlice(httptest.NewRequest(http.MethodDelete, "/favorites/some-id", nil)))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Returns 404 deleting an unknown favorite", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockfavorite.NewMockFavoriteStore(ctrl)
+		s.EXPECT().Delete(gomock.Any(), "alice", "some-id").Return(favorite.ErrNotFound)
+
+		rr := httptest.NewRecorder()
+		favoritesRouter(t, s).ServeHTTP(rr, asAlice(httptest.NewRequest(http.MethodDelete, "/favorites/some-id", nil)))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"github.com/matthewjamesboyle/catserver/internal/problem"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// parseImageFilter reads ?breed=, ?category=, ?mime_types= and ?size= and
+// checks them against what the image upstream accepts. Breeds are only
+// checked when breeds is set, and let through if the list can't be fetched.
+func parseImageFilter(req *http.Request, breeds cat.BreedLister) (cat.ImageFilter, error) {
+	q := req.URL.Query()
+	f := cat.ImageFilter{
+		Breed: q.Get("breed"),
+		Size:  q.Get("size"),
+	}
+	if c := q.Get("category"); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n < 1 {
+			return cat.ImageFilter{}, cat.ErrInvalidFilter{Param: "category", Value: c}
+		}
+		f.Category = n
+	}
+	for _, m := range strings.Split(q.Get("mime_types"), ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			f.MIMETypes = append(f.MIMETypes, m)
+		}
+	}
+
+	if err := f.Validate(); err != nil {
+		return cat.ImageFilter{}, err
+	}
+	if breeds == nil {
+		return f, nil
+	}
+
+	err := f.ValidateBreed(req.Context(), breeds)
+	var ub cat.ErrUnknownBreed
+	switch {
+	case errors.As(err, &ub):
+		return cat.ImageFilter{}, err
+	case err != nil:
+		logging.FromContext(req.Context()).Warn("listing breeds", "error", err)
+	}
+	return f, nil
+}
+
+// writeFilterProblem reports a bad filter from parseImageFilter.
+func writeFilterProblem(w http.ResponseWriter, err error) {
+	var inv cat.ErrInvalidFilter
+	var ub cat.ErrUnknownBreed
+	switch {
+	case errors.As(err, &inv):
+		problem.Write(w, problem.Problem{
+			Type:    "/problems/invalid-filter",
+			Status:  http.StatusBadRequest,
+			Detail:  err.Error(),
+			Param:   inv.Param,
+			Value:   inv.Value,
+			Allowed: inv.Allowed,
+		})
+	case errors.As(err, &ub):
+		problem.Write(w, problem.Problem{
+			Type:   "/problems/unknown-breed",
+			Status: http.StatusBadRequest,
+			Detail: err.Error() + ", see /breeds",
+			Param:  "breed",
+			Value:  ub.Breed,
+		})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,149 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"net/http"
+)
+
+const (
+	DefaultMaxDepth      = 5
+	DefaultMaxComplexity = 100
+)
+
+type Handler struct {
+	schema        graphql.Schema
+	maxDepth      int
+	maxComplexity int
+	batchScope    string
+}
+
+type Option func(h *Handler)
+
+// WithMaxDepth limits how deeply a query may nest selections.
+func WithMaxDepth(n int) Option {
+	return func(h *Handler) {
+		h.maxDepth = n
+	}
+}
+
+// WithMaxComplexity limits the estimated number of fields a query may resolve.
+func WithMaxComplexity(n int) Option {
+	return func(h *Handler) {
+		h.maxComplexity = n
+	}
+}
+
+// WithBatchScope makes the cats field, which fetches several results at
+// once, require an API key granted scope.
+func WithBatchScope(scope string) Option {
+	return func(h *Handler) {
+		h.batchScope = scope
+	}
+}
+
+func NewHandler(f cat.FactGetter, i cat.ImageGetter, opts ...Option) (*Handler, error) {
+	if f == nil {
+		return nil, cat.ErrNilParam{Parameter: "FactGetter"}
+	}
+	if i == nil {
+		return nil, cat.ErrNilParam{Parameter: "ImageGetter"}
+	}
+
+	h := &Handler{
+		maxDepth:      DefaultMaxDepth,
+		maxComplexity: DefaultMaxComplexity,
+	}
+	for _, o := range opts {
+		o(h)
+	}
+
+	s, err := newSchema(f, i, h.batchScope)
+	if err != nil {
+		return nil, err
+	}
+	h.schema = s
+	return h, nil
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var r request
+	switch req.Method {
+	case http.MethodGet:
+		r.Query = req.URL.Query().Get("query")
+		r.OperationName = req.URL.Query().Get("operationName")
+		if v := req.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &r.Variables); err != nil {
+				writeErrors(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			writeErrors(w, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.checkLimits(r); err != nil {
+		writeErrors(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  r.Query,
+		VariableValues: r.Variables,
+		OperationName:  r.OperationName,
+		Context:        req.Context(),
+	})
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+}
+
+func (h *Handler) checkLimits(r request) error {
+	if r.Query == "" {
+		return errors.New("query is required")
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(r.Query)})})
+	if err != nil {
+		return err
+	}
+
+	depth, complexity := analyze(doc, r.Variables)
+	if h.maxDepth > 0 && depth > h.maxDepth {
+		return ErrQueryTooDeep{Depth: depth, Max: h.maxDepth}
+	}
+	if h.maxComplexity > 0 && complexity > h.maxComplexity {
+		return ErrQueryTooComplex{Complexity: complexity, Max: h.maxComplexity}
+	}
+	return nil
+}
+
+func writeErrors(w http.ResponseWriter, code int, err error) {
+	b, _ := json.Marshal(graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)}})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(b)
+}
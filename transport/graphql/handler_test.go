@@ -0,0 +1,251 @@
+package graphql_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	"github.com/matthewjamesboyle/catserver/transport/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func post(t *testing.T, h http.Handler, query string, variables map[string]interface{}) (int, response) {
+	t.Helper()
+
+	b, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(b)))
+
+	var res response
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+	return rr.Code, res
+}
+
+func TestNewHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("Returns a Handler", func(t *testing.T) {
+		h, err := graphql.NewHandler(mockcat.NewMockFactGetter(ctrl), mockcat.NewMockImageGetter(ctrl))
+
+		assert.NoError(t, err)
+		assert.NotNil(t, h)
+	})
+
+	t.Run("Returns an error given a nil FactGetter", func(t *testing.T) {
+		h, err := graphql.NewHandler(nil, mockcat.NewMockImageGetter(ctrl))
+
+		assert.Nil(t, h)
+		var e cat.ErrNilParam
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, "FactGetter", e.Parameter)
+	})
+
+	t.Run("Returns an error given a nil ImageGetter", func(t *testing.T) {
+		h, err := graphql.NewHandler(mockcat.NewMockFactGetter(ctrl), nil)
+
+		assert.Nil(t, h)
+		var e cat.ErrNilParam
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, "ImageGetter", e.Parameter)
+	})
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	t.Run("Only calls the FactGetter when only the fact is selected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		i := mockcat.NewMockImageGetter(ctrl)
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("some-fact"), nil)
+		i.EXPECT().GetImage(gomock.Any()).Times(0)
+
+		h, err := graphql.NewHandler(f, i)
+		require.NoError(t, err)
+
+		code, res := post(t, h, `{ cat { fact } }`, nil)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, res.Errors)
+		assert.Equal(t, map[string]interface{}{"fact": "some-fact"}, res.Data["cat"])
+	})
+
+	t.Run("Returns the fact and image url", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		i := mockcat.NewMockImageGetter(ctrl)
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("some-fact"), nil)
+		i.EXPECT().GetImage(gomock.Any()).Return(cat.Image{URL: "http://someurl"}, nil)
+
+		h, err := graphql.NewHandler(f, i)
+		require.NoError(t, err)
+
+		code, res := post(t, h, `{ cat { fact image { url } } }`, nil)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, res.Errors)
+		assert.Equal(t, map[string]interface{}{
+			"fact":  "some-fact",
+			"image": map[string]interface{}{"url": "http://someurl"},
+		}, res.Data["cat"])
+	})
+
+	t.Run("Returns image metadata, with null for what the upstream doesn't know", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		i := mockcat.NewMockImageGetter(ctrl)
+		i.EXPECT().GetImage(gomock.Any()).Return(cat.Image{
+			ID:     "abc",
+			URL:    "http://someurl",
+			Width:  640,
+			Height: 480,
+			Breeds: []cat.Breed{{ID: "beng", Name: "Bengal"}},
+		}, nil)
+
+		h, err := graphql.NewHandler(mockcat.NewMockFactGetter(ctrl), i)
+		require.NoError(t, err)
+
+		code, res := post(t, h, `{ cat { image { id width height mimeType breeds { id name origin } } } }`, nil)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, res.Errors)
+		assert.Equal(t, map[string]interface{}{
+			"image": map[string]interface{}{
+				"id":       "abc",
+				"width":    float64(640),
+				"height":   float64(480),
+				"mimeType": nil,
+				"breeds":   []interface{}{map[string]interface{}{"id": "beng", "name": "Bengal", "origin": nil}},
+			},
+		}, res.Data["cat"])
+	})
+
+	t.Run("Returns count cats", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("some-fact"), nil).Times(3)
+
+		h, err := graphql.NewHandler(f, mockcat.NewMockImageGetter(ctrl))
+		require.NoError(t, err)
+
+		code, res := post(t, h, `query($n: Int) { cats(count: $n) { fact } }`, map[string]interface{}{"n": 3})
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, res.Errors)
+		assert.Len(t, res.Data["cats"], 3)
+	})
+
+	t.Run("Requires the batch scope for cats given WithBatchScope", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("some-fact"), nil).Times(2)
+
+		h, err := graphql.NewHandler(f, mockcat.NewMockImageGetter(ctrl), graphql.WithBatchScope(auth.ScopeBatch))
+		require.NoError(t, err)
+
+		send := func(scopes ...string) response {
+			b, err := json.Marshal(map[string]interface{}{"query": `{ cats(count: 2) { fact } }`})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(b))
+			req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{KeyID: "k1", Scopes: scopes}))
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			var res response
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+			return res
+		}
+
+		res := send(auth.ScopeRead)
+		require.Len(t, res.Errors, 1)
+		assert.Contains(t, res.Errors[0].Message, auth.ScopeBatch)
+
+		res = send(auth.ScopeRead, auth.ScopeBatch)
+		assert.Empty(t, res.Errors)
+		assert.Len(t, res.Data["cats"], 2)
+	})
+
+	t.Run("Returns an error given the FactGetter fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact(""), errors.New("some-error"))
+
+		h, err := graphql.NewHandler(f, mockcat.NewMockImageGetter(ctrl))
+		require.NoError(t, err)
+
+		_, res := post(t, h, `{ cat { fact } }`, nil)
+
+		require.Len(t, res.Errors, 1)
+		assert.Contains(t, res.Errors[0].Message, "some-error")
+	})
+
+	t.Run("Supports GET requests", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := mockcat.NewMockFactGetter(ctrl)
+		f.EXPECT().GetFact(gomock.Any()).Return(cat.Fact("some-fact"), nil)
+
+		h, err := graphql.NewHandler(f, mockcat.NewMockImageGetter(ctrl))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`{ cat { fact } }`), nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Rejects queries that are too complex without calling upstreams", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		h, err := graphql.NewHandler(mockcat.NewMockFactGetter(ctrl), mockcat.NewMockImageGetter(ctrl), graphql.WithMaxComplexity(10))
+		require.NoError(t, err)
+
+		code, res := post(t, h, `{ cats(count: 5) { fact image { url } } }`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		require.Len(t, res.Errors, 1)
+		assert.Contains(t, res.Errors[0].Message, "complexity")
+	})
+
+	t.Run("Rejects queries that are too deep", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		h, err := graphql.NewHandler(mockcat.NewMockFactGetter(ctrl), mockcat.NewMockImageGetter(ctrl), graphql.WithMaxDepth(2))
+		require.NoError(t, err)
+
+		code, res := post(t, h, `{ cat { image { url } } }`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		require.Len(t, res.Errors, 1)
+		assert.Contains(t, res.Errors[0].Message, "depth")
+	})
+}
@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"fmt"
+	"github.com/graphql-go/graphql/language/ast"
+	"strconv"
+)
+
+type ErrQueryTooDeep struct {
+	Depth, Max int
+}
+
+func (e ErrQueryTooDeep) Error() string {
+	return fmt.Sprintf("query depth %d exceeds the maximum of %d", e.Depth, e.Max)
+}
+
+type ErrQueryTooComplex struct {
+	Complexity, Max int
+}
+
+func (e ErrQueryTooComplex) Error() string {
+	return fmt.Sprintf("query complexity %d exceeds the maximum of %d", e.Complexity, e.Max)
+}
+
+// analyzer walks a parsed query and works out how deep it goes and roughly
+// how many fields it will resolve. Every field costs one, and the cost of a
+// field with a count argument is multiplied by that count.
+type analyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+func analyze(doc *ast.Document, variables map[string]interface{}) (depth, complexity int) {
+	a := analyzer{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		visiting:  make(map[string]bool),
+	}
+	for _, d := range doc.Definitions {
+		if f, ok := d.(*ast.FragmentDefinition); ok {
+			a.fragments[f.Name.Value] = f
+		}
+	}
+	for _, d := range doc.Definitions {
+		if op, ok := d.(*ast.OperationDefinition); ok {
+			d, c := a.selectionSet(op.SelectionSet)
+			if d > depth {
+				depth = d
+			}
+			complexity += c
+		}
+	}
+	return depth, complexity
+}
+
+func (a *analyzer) selectionSet(set *ast.SelectionSet) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, s := range set.Selections {
+		var d, c int
+		switch s := s.(type) {
+		case *ast.Field:
+			d, c = a.selectionSet(s.SelectionSet)
+			d++
+			c = (c + 1) * a.multiplier(s)
+		case *ast.InlineFragment:
+			d, c = a.selectionSet(s.SelectionSet)
+		case *ast.FragmentSpread:
+			f, ok := a.fragments[s.Name.Value]
+			if !ok || a.visiting[s.Name.Value] {
+				continue
+			}
+			a.visiting[s.Name.Value] = true
+			d, c = a.selectionSet(f.SelectionSet)
+			a.visiting[s.Name.Value] = false
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity += c
+	}
+	return depth, complexity
+}
+
+func (a *analyzer) multiplier(f *ast.Field) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "count" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 1 {
+				return n
+			}
+		case *ast.Variable:
+			switch n := a.variables[v.Name.Value].(type) {
+			case float64:
+				if n > 1 {
+					return int(n)
+				}
+			case int:
+				if n > 1 {
+					return n
+				}
+			}
+		}
+	}
+	return 1
+}
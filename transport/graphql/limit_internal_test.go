@@ -0,0 +1,36 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		variables  map[string]interface{}
+		depth      int
+		complexity int
+	}{
+		{name: "single field", query: `{ cat { fact } }`, depth: 2, complexity: 2},
+		{name: "nested", query: `{ cat { fact image { url } } }`, depth: 3, complexity: 4},
+		{name: "count multiplies", query: `{ cats(count: 3) { fact } }`, depth: 2, complexity: 6},
+		{name: "count from variable", query: `query($n: Int) { cats(count: $n) { fact } }`, variables: map[string]interface{}{"n": float64(4)}, depth: 2, complexity: 8},
+		{name: "fragments", query: `{ cat { ...f } } fragment f on Cat { image { url } }`, depth: 3, complexity: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			require.NoError(t, err)
+
+			d, c := analyze(doc, tt.variables)
+
+			assert.Equal(t, tt.depth, d)
+			assert.Equal(t, tt.complexity, c)
+		})
+	}
+}
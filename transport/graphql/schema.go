@@ -0,0 +1,192 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"sync"
+)
+
+// MaxCats is the largest count accepted by the cats field.
+const MaxCats = 20
+
+// lazyCat fetches its fact and image only when a query selects them, so a
+// query for just the fact never calls the ImageGetter.
+type lazyCat struct {
+	ctx  context.Context
+	fact cat.FactGetter
+	img  cat.ImageGetter
+
+	factOnce sync.Once
+	f        cat.Fact
+	factErr  error
+
+	imgOnce sync.Once
+	i       cat.Image
+	imgErr  error
+}
+
+func (c *lazyCat) getFact() (cat.Fact, error) {
+	c.factOnce.Do(func() {
+		c.f, c.factErr = c.fact.GetFact(c.ctx)
+	})
+	return c.f, c.factErr
+}
+
+func (c *lazyCat) getImage() (cat.Image, error) {
+	c.imgOnce.Do(func() {
+		c.i, c.imgErr = c.img.GetImage(c.ctx)
+	})
+	return c.i, c.imgErr
+}
+
+// async starts fn straight away and returns a thunk, letting graphql-go
+// resolve sibling fields concurrently.
+func async(fn func() (interface{}, error)) func() (interface{}, error) {
+	var (
+		v    interface{}
+		err  error
+		done = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		v, err = fn()
+	}()
+	return func() (interface{}, error) {
+		<-done
+		return v, err
+	}
+}
+
+func imageField(fn func(cat.Image) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(cat.Image)), nil
+	}
+}
+
+func breedField(fn func(cat.Breed) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(cat.Breed)), nil
+	}
+}
+
+// optional maps the zero values upstreams use for "unknown" to null.
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func optionalInt(n int) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+// newSchema builds the schema. With a batchScope, the cats field needs an
+// identity granted it.
+func newSchema(f cat.FactGetter, i cat.ImageGetter, batchScope string) (graphql.Schema, error) {
+	breedType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Breed",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: breedField(func(b cat.Breed) interface{} { return b.ID })},
+			"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: breedField(func(b cat.Breed) interface{} { return b.Name })},
+			"origin":      &graphql.Field{Type: graphql.String, Resolve: breedField(func(b cat.Breed) interface{} { return optional(b.Origin) })},
+			"temperament": &graphql.Field{Type: graphql.String, Resolve: breedField(func(b cat.Breed) interface{} { return optional(b.Temperament) })},
+		},
+	})
+
+	imageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Image",
+		Fields: graphql.Fields{
+			"url":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: imageField(func(i cat.Image) interface{} { return string(i.URL) })},
+			"id":       &graphql.Field{Type: graphql.String, Resolve: imageField(func(i cat.Image) interface{} { return optional(i.ID) })},
+			"width":    &graphql.Field{Type: graphql.Int, Resolve: imageField(func(i cat.Image) interface{} { return optionalInt(i.Width) })},
+			"height":   &graphql.Field{Type: graphql.Int, Resolve: imageField(func(i cat.Image) interface{} { return optionalInt(i.Height) })},
+			"mimeType": &graphql.Field{Type: graphql.String, Resolve: imageField(func(i cat.Image) interface{} { return optional(i.MIMEType) })},
+			"breeds": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(breedType))),
+				Resolve: imageField(func(i cat.Image) interface{} { return append([]cat.Breed{}, i.Breeds...) }),
+			},
+		},
+	})
+
+	catType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Cat",
+		Fields: graphql.Fields{
+			"fact": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(*lazyCat)
+					return async(func() (interface{}, error) {
+						ft, err := c.getFact()
+						if err != nil {
+							return nil, fmt.Errorf("GetFact: %w", err)
+						}
+						return string(ft), nil
+					}), nil
+				},
+			},
+			"image": &graphql.Field{
+				Type: graphql.NewNonNull(imageType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(*lazyCat)
+					return async(func() (interface{}, error) {
+						it, err := c.getImage()
+						if err != nil {
+							return nil, fmt.Errorf("GetImage: %w", err)
+						}
+						return it, nil
+					}), nil
+				},
+			},
+		},
+	})
+
+	newCat := func(ctx context.Context) *lazyCat {
+		return &lazyCat{ctx: ctx, fact: f, img: i}
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"cat": &graphql.Field{
+				Type: graphql.NewNonNull(catType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return newCat(p.Context), nil
+				},
+			},
+			"cats": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(catType))),
+				Args: graphql.FieldConfigArgument{
+					"count": &graphql.ArgumentConfig{
+						Type:         graphql.Int,
+						DefaultValue: 1,
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if batchScope != "" {
+						if id, _ := auth.IdentityFromContext(p.Context); !id.HasScope(batchScope) {
+							return nil, auth.ErrMissingScope{Scope: batchScope}
+						}
+					}
+					count, _ := p.Args["count"].(int)
+					if count < 1 || count > MaxCats {
+						return nil, fmt.Errorf("count must be between 1 and %d", MaxCats)
+					}
+					cats := make([]*lazyCat, count)
+					for n := range cats {
+						cats[n] = newCat(p.Context)
+					}
+					return cats, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
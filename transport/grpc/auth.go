@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyMetadata is the metadata key clients send their API key in.
+const APIKeyMetadata = "x-api-key"
+
+// Auth checks API keys against the same auth.KeyStore as the HTTP API.
+// Unlike HTTP routes, methods are refused unless given a scope with
+// Require, so a new RPC is never public by accident.
+type Auth struct {
+	store  auth.KeyStore
+	scopes map[string]string
+}
+
+func NewAuth(store auth.KeyStore) (*Auth, error) {
+	if store == nil {
+		return nil, cat.ErrNilParam{Parameter: "store"}
+	}
+	return &Auth{store: store, scopes: make(map[string]string)}, nil
+}
+
+// Require makes the method with the given full name, e.g.
+// catpb.CatService_StreamCats_FullMethodName, require scope.
+func (a *Auth) Require(method, scope string) {
+	a.scopes[method] = scope
+}
+
+// Unary is installed with grpc.ChainUnaryInterceptor.
+func (a *Auth) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream is installed with grpc.ChainStreamInterceptor.
+func (a *Auth) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *Auth) authorize(ctx context.Context, method string) (context.Context, error) {
+	scope, ok := a.scopes[method]
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, "method not available")
+	}
+
+	var key string
+	if v := metadata.ValueFromIncomingContext(ctx, APIKeyMetadata); len(v) > 0 {
+		key = v[0]
+	}
+	id, err := auth.Authorize(a.store, key, scope)
+	var ms auth.ErrMissingScope
+	switch {
+	case errors.As(err, &ms):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, auth.ErrKeyRequired), errors.Is(err, auth.ErrKeyExpired):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Unauthenticated, "invalid api key")
+	}
+	return auth.ContextWithIdentity(ctx, id), nil
+}
+
+// identityStream carries the caller's identity in the stream's context.
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,85 @@
+package grpc_test
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/auth"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockauth"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	catgrpc "github.com/matthewjamesboyle/catserver/transport/grpc"
+	"github.com/matthewjamesboyle/catserver/transport/grpc/catpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestNewAuth(t *testing.T) {
+	a, err := catgrpc.NewAuth(nil)
+
+	assert.Nil(t, a)
+	assert.Error(t, err)
+}
+
+func TestAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockauth.NewMockKeyStore(ctrl)
+	store.EXPECT().Lookup("reader").Return(auth.Identity{KeyID: "r", Scopes: []string{auth.ScopeRead}}, nil).AnyTimes()
+	store.EXPECT().Lookup("batcher").Return(auth.Identity{KeyID: "b", Scopes: []string{auth.ScopeRead, auth.ScopeBatch}}, nil).AnyTimes()
+	store.EXPECT().Lookup("made-up").Return(auth.Identity{}, auth.ErrUnknownKey).AnyTimes()
+
+	a, err := catgrpc.NewAuth(store)
+	require.NoError(t, err)
+	a.Require(catpb.CatService_GetImageAndFact_FullMethodName, auth.ScopeRead)
+	a.Require(catpb.CatService_StreamCats_FullMethodName, auth.ScopeBatch)
+
+	s := mockcat.NewMockServicer(ctrl)
+	var got auth.Identity
+	s.EXPECT().GetImageAndFact(gomock.Any()).DoAndReturn(func(ctx context.Context) (cat.CatResult, error) {
+		got, _ = auth.IdentityFromContext(ctx)
+		return cat.CatResult{Fact: "some-fact"}, nil
+	}).AnyTimes()
+	c := newClient(t, s, grpc.ChainUnaryInterceptor(a.Unary()), grpc.ChainStreamInterceptor(a.Stream()))
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), catgrpc.APIKeyMetadata, key)
+	}
+
+	t.Run("Rejects calls without a valid key", func(t *testing.T) {
+		_, err := c.GetImageAndFact(context.Background(), &catpb.GetImageAndFactRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = c.GetImageAndFact(withKey("made-up"), &catpb.GetImageAndFactRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Puts the identity on the context given a valid key", func(t *testing.T) {
+		_, err := c.GetImageAndFact(withKey("reader"), &catpb.GetImageAndFactRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "r", got.KeyID)
+	})
+
+	t.Run("Requires the batch scope to stream", func(t *testing.T) {
+		stream, err := c.StreamCats(withKey("reader"), &catpb.StreamCatsRequest{Count: 1})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		stream, err = c.StreamCats(withKey("batcher"), &catpb.StreamCatsRequest{Count: 1})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "b", got.KeyID)
+	})
+
+	t.Run("Refuses methods without a scope", func(t *testing.T) {
+		_, err := c.BatchGetImageAndFact(withKey("batcher"), &catpb.BatchGetImageAndFactRequest{Count: 1})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
@@ -0,0 +1,659 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: transport/grpc/catpb/cat.proto
+
+package catpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CatResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ImageUrl string `protobuf:"bytes,1,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	Fact     string `protobuf:"bytes,2,opt,name=fact,proto3" json:"fact,omitempty"`
+	// id is the permalink ID, if permalinks are enabled.
+	Id string `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	// image describes the picture at image_url, as far as the upstream knows.
+	Image *Image `protobuf:"bytes,4,opt,name=image,proto3" json:"image,omitempty"`
+}
+
+func (x *CatResult) Reset() {
+	*x = CatResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transport_grpc_catpb_cat_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CatResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatResult) ProtoMessage() {}
+
+func (x *CatResult) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_grpc_catpb_cat_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatResult.ProtoReflect.Descriptor instead.
+func (*CatResult) Descriptor() ([]byte, []int) {
+	return file_transport_grpc_catpb_cat_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CatResult) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+func (x *CatResult) GetFact() string {
+	if x != nil {
+		return x.Fact
+	}
+	return ""
+}
+
+func (x *CatResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CatResult) GetImage() *Image {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+type Image struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url      string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Width    uint32   `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height   uint32   `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	MimeType string   `protobuf:"bytes,5,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Breeds   []*Breed `protobuf:"bytes,6,rep,name=breeds,proto3" json:"breeds,omitempty"`
+}
+
+func (x *Image) Reset() {
+	*x = Image{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transport_grpc_catpb_cat_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Image) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Image) ProtoMessage() {}
+
+func (x *Image) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_grpc_catpb_cat_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Image.ProtoReflect.Descriptor instead.
+func (*Image) Descriptor() ([]byte, []int) {
+	return file_transport_grpc_catpb_cat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Image) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Image) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Image) GetWidth() uint32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Image) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Image) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *Image) GetBreeds() []*Breed {
+	if x != nil {
+		return x.Breeds
+	}
+	return nil
+}
+
+type Breed struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Origin      string `protobuf:"bytes,3,opt,name=origin,proto3" json:"origin,omitempty"`
+	Temperament string `protobuf:"bytes,4,opt,name=temperament,proto3" json:"temperament,omitempty"`
+}
+
+func (x *Breed) Reset() {
+	*x = Breed{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transport_grpc_catpb_cat_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Breed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Breed) ProtoMessage() {}
+
+func (x *Breed) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_grpc_catpb_cat_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Breed.ProtoReflect.Descriptor instead.
+func (*Breed) Descriptor() ([]byte, []int) {
+	return file_transport_grpc_catpb_cat_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Breed) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Breed) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Breed) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *Breed) GetTemperament() string {
+	if x != nil {
+		return x.Temperament
+	}
+	return ""
+}
+
+type GetImageAndFactRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetImageAndFactRequest) Reset() {
+	*x = GetImageAndFactRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transport_grpc_catpb_cat_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetImageAndFactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImageAndFactRequest) ProtoMessage() {}
+
+func (x *GetImageAndFactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_grpc_catpb_cat_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImageAndFactRequest.ProtoReflect.Descriptor instead.
+func (*GetImageAndFactRequest) Descriptor() ([]byte, []int) {
+	return file_transport_grpc_catpb_cat_proto_rawDescGZIP(), []int{3}
+}
+
+type StreamCatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// count is the number of results to send, at most 1000. Zero streams until
+	// the client cancels.
+	Count uint32 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	// interval_ms is the pause between results. Anything under 1000 is raised
+	// to 1000.
+	IntervalMs uint32 `protobuf:"varint,2,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+}
+
+func (x *StreamCatsRequest) Reset() {
+	*x = StreamCatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transport_grpc_catpb_cat_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamCatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamCatsRequest) ProtoMessage() {}
+
+func (x *StreamCatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_grpc_catpb_cat_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamCatsRequest.ProtoReflect.Descriptor instead.
+func (*StreamCatsRequest) Descriptor() ([]byte, []int) {
+	return file_transport_grpc_catpb_cat_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamCatsRequest) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *StreamCatsRequest) GetIntervalMs() uint32 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+type BatchGetImageAndFactRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Count uint32 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *BatchGetImageAndFactRequest) Reset() {
+	*x = BatchGetImageAndFactRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transport_grpc_catpb_cat_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetImageAndFactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetImageAndFactRequest) ProtoMessage() {}
+
+func (x *BatchGetImageAndFactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_grpc_catpb_cat_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetImageAndFactRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetImageAndFactRequest) Descriptor() ([]byte, []int) {
+	return file_transport_grpc_catpb_cat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchGetImageAndFactRequest) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type BatchGetImageAndFactResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*CatResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *BatchGetImageAndFactResponse) Reset() {
+	*x = BatchGetImageAndFactResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transport_grpc_catpb_cat_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetImageAndFactResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetImageAndFactResponse) ProtoMessage() {}
+
+func (x *BatchGetImageAndFactResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_grpc_catpb_cat_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetImageAndFactResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetImageAndFactResponse) Descriptor() ([]byte, []int) {
+	return file_transport_grpc_catpb_cat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchGetImageAndFactResponse) GetResults() []*CatResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+var File_transport_grpc_catpb_cat_proto protoreflect.FileDescriptor
+
+var file_transport_grpc_catpb_cat_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x2f, 0x67, 0x72, 0x70, 0x63,
+	0x2f, 0x63, 0x61, 0x74, 0x70, 0x62, 0x2f, 0x63, 0x61, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x06, 0x63, 0x61, 0x74, 0x2e, 0x76, 0x31, 0x22, 0x71, 0x0a, 0x09, 0x43, 0x61, 0x74, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x75,
+	0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x55,
+	0x72, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x61, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x66, 0x61, 0x63, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x23, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x63, 0x61, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x49,
+	0x6d, 0x61, 0x67, 0x65, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x22, 0x9b, 0x01, 0x0a, 0x05,
+	0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x16, 0x0a,
+	0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x6d, 0x65, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x69, 0x6d, 0x65, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x25, 0x0a, 0x06, 0x62, 0x72, 0x65, 0x65, 0x64, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x63, 0x61, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x72, 0x65, 0x65,
+	0x64, 0x52, 0x06, 0x62, 0x72, 0x65, 0x65, 0x64, 0x73, 0x22, 0x65, 0x0a, 0x05, 0x42, 0x72, 0x65,
+	0x65, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x12, 0x20,
+	0x0a, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x6d, 0x65, 0x6e, 0x74,
+	0x22, 0x18, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x6e, 0x64, 0x46,
+	0x61, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x4a, 0x0a, 0x11, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x43, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61,
+	0x6c, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x76, 0x61, 0x6c, 0x4d, 0x73, 0x22, 0x33, 0x0a, 0x1b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47,
+	0x65, 0x74, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x6e, 0x64, 0x46, 0x61, 0x63, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x4b, 0x0a, 0x1c, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x6e, 0x64, 0x46,
+	0x61, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x07, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63,
+	0x61, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52,
+	0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x32, 0xf3, 0x01, 0x0a, 0x0a, 0x43, 0x61, 0x74,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x44, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x49, 0x6d,
+	0x61, 0x67, 0x65, 0x41, 0x6e, 0x64, 0x46, 0x61, 0x63, 0x74, 0x12, 0x1e, 0x2e, 0x63, 0x61, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x6e, 0x64, 0x46,
+	0x61, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x63, 0x61, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x3c, 0x0a,
+	0x0a, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x43, 0x61, 0x74, 0x73, 0x12, 0x19, 0x2e, 0x63, 0x61,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x43, 0x61, 0x74, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x63, 0x61, 0x74, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x61, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x30, 0x01, 0x12, 0x61, 0x0a, 0x14, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x6e, 0x64, 0x46,
+	0x61, 0x63, 0x74, 0x12, 0x23, 0x2e, 0x63, 0x61, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x47, 0x65, 0x74, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x6e, 0x64, 0x46, 0x61, 0x63,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x63, 0x61, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41,
+	0x6e, 0x64, 0x46, 0x61, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x3d,
+	0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x61, 0x74,
+	0x74, 0x68, 0x65, 0x77, 0x6a, 0x61, 0x6d, 0x65, 0x73, 0x62, 0x6f, 0x79, 0x6c, 0x65, 0x2f, 0x63,
+	0x61, 0x74, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f,
+	0x72, 0x74, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x63, 0x61, 0x74, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_transport_grpc_catpb_cat_proto_rawDescOnce sync.Once
+	file_transport_grpc_catpb_cat_proto_rawDescData = file_transport_grpc_catpb_cat_proto_rawDesc
+)
+
+func file_transport_grpc_catpb_cat_proto_rawDescGZIP() []byte {
+	file_transport_grpc_catpb_cat_proto_rawDescOnce.Do(func() {
+		file_transport_grpc_catpb_cat_proto_rawDescData = protoimpl.X.CompressGZIP(file_transport_grpc_catpb_cat_proto_rawDescData)
+	})
+	return file_transport_grpc_catpb_cat_proto_rawDescData
+}
+
+var file_transport_grpc_catpb_cat_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_transport_grpc_catpb_cat_proto_goTypes = []any{
+	(*CatResult)(nil),                    // 0: cat.v1.CatResult
+	(*Image)(nil),                        // 1: cat.v1.Image
+	(*Breed)(nil),                        // 2: cat.v1.Breed
+	(*GetImageAndFactRequest)(nil),       // 3: cat.v1.GetImageAndFactRequest
+	(*StreamCatsRequest)(nil),            // 4: cat.v1.StreamCatsRequest
+	(*BatchGetImageAndFactRequest)(nil),  // 5: cat.v1.BatchGetImageAndFactRequest
+	(*BatchGetImageAndFactResponse)(nil), // 6: cat.v1.BatchGetImageAndFactResponse
+}
+var file_transport_grpc_catpb_cat_proto_depIdxs = []int32{
+	1, // 0: cat.v1.CatResult.image:type_name -> cat.v1.Image
+	2, // 1: cat.v1.Image.breeds:type_name -> cat.v1.Breed
+	0, // 2: cat.v1.BatchGetImageAndFactResponse.results:type_name -> cat.v1.CatResult
+	3, // 3: cat.v1.CatService.GetImageAndFact:input_type -> cat.v1.GetImageAndFactRequest
+	4, // 4: cat.v1.CatService.StreamCats:input_type -> cat.v1.StreamCatsRequest
+	5, // 5: cat.v1.CatService.BatchGetImageAndFact:input_type -> cat.v1.BatchGetImageAndFactRequest
+	0, // 6: cat.v1.CatService.GetImageAndFact:output_type -> cat.v1.CatResult
+	0, // 7: cat.v1.CatService.StreamCats:output_type -> cat.v1.CatResult
+	6, // 8: cat.v1.CatService.BatchGetImageAndFact:output_type -> cat.v1.BatchGetImageAndFactResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_transport_grpc_catpb_cat_proto_init() }
+func file_transport_grpc_catpb_cat_proto_init() {
+	if File_transport_grpc_catpb_cat_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_transport_grpc_catpb_cat_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*CatResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transport_grpc_catpb_cat_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Image); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transport_grpc_catpb_cat_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Breed); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transport_grpc_catpb_cat_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*GetImageAndFactRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transport_grpc_catpb_cat_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*StreamCatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transport_grpc_catpb_cat_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*BatchGetImageAndFactRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transport_grpc_catpb_cat_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*BatchGetImageAndFactResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_transport_grpc_catpb_cat_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_transport_grpc_catpb_cat_proto_goTypes,
+		DependencyIndexes: file_transport_grpc_catpb_cat_proto_depIdxs,
+		MessageInfos:      file_transport_grpc_catpb_cat_proto_msgTypes,
+	}.Build()
+	File_transport_grpc_catpb_cat_proto = out.File
+	file_transport_grpc_catpb_cat_proto_rawDesc = nil
+	file_transport_grpc_catpb_cat_proto_goTypes = nil
+	file_transport_grpc_catpb_cat_proto_depIdxs = nil
+}
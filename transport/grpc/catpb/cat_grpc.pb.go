@@ -0,0 +1,214 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: transport/grpc/catpb/cat.proto
+
+package catpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	CatService_GetImageAndFact_FullMethodName      = "/cat.v1.CatService/GetImageAndFact"
+	CatService_StreamCats_FullMethodName           = "/cat.v1.CatService/StreamCats"
+	CatService_BatchGetImageAndFact_FullMethodName = "/cat.v1.CatService/BatchGetImageAndFact"
+)
+
+// CatServiceClient is the client API for CatService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CatServiceClient interface {
+	GetImageAndFact(ctx context.Context, in *GetImageAndFactRequest, opts ...grpc.CallOption) (*CatResult, error)
+	StreamCats(ctx context.Context, in *StreamCatsRequest, opts ...grpc.CallOption) (CatService_StreamCatsClient, error)
+	BatchGetImageAndFact(ctx context.Context, in *BatchGetImageAndFactRequest, opts ...grpc.CallOption) (*BatchGetImageAndFactResponse, error)
+}
+
+type catServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCatServiceClient(cc grpc.ClientConnInterface) CatServiceClient {
+	return &catServiceClient{cc}
+}
+
+func (c *catServiceClient) GetImageAndFact(ctx context.Context, in *GetImageAndFactRequest, opts ...grpc.CallOption) (*CatResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CatResult)
+	err := c.cc.Invoke(ctx, CatService_GetImageAndFact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catServiceClient) StreamCats(ctx context.Context, in *StreamCatsRequest, opts ...grpc.CallOption) (CatService_StreamCatsClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CatService_ServiceDesc.Streams[0], CatService_StreamCats_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &catServiceStreamCatsClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CatService_StreamCatsClient interface {
+	Recv() (*CatResult, error)
+	grpc.ClientStream
+}
+
+type catServiceStreamCatsClient struct {
+	grpc.ClientStream
+}
+
+func (x *catServiceStreamCatsClient) Recv() (*CatResult, error) {
+	m := new(CatResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *catServiceClient) BatchGetImageAndFact(ctx context.Context, in *BatchGetImageAndFactRequest, opts ...grpc.CallOption) (*BatchGetImageAndFactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetImageAndFactResponse)
+	err := c.cc.Invoke(ctx, CatService_BatchGetImageAndFact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CatServiceServer is the server API for CatService service.
+// All implementations must embed UnimplementedCatServiceServer
+// for forward compatibility
+type CatServiceServer interface {
+	GetImageAndFact(context.Context, *GetImageAndFactRequest) (*CatResult, error)
+	StreamCats(*StreamCatsRequest, CatService_StreamCatsServer) error
+	BatchGetImageAndFact(context.Context, *BatchGetImageAndFactRequest) (*BatchGetImageAndFactResponse, error)
+	mustEmbedUnimplementedCatServiceServer()
+}
+
+// UnimplementedCatServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCatServiceServer struct {
+}
+
+func (UnimplementedCatServiceServer) GetImageAndFact(context.Context, *GetImageAndFactRequest) (*CatResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetImageAndFact not implemented")
+}
+func (UnimplementedCatServiceServer) StreamCats(*StreamCatsRequest, CatService_StreamCatsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamCats not implemented")
+}
+func (UnimplementedCatServiceServer) BatchGetImageAndFact(context.Context, *BatchGetImageAndFactRequest) (*BatchGetImageAndFactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetImageAndFact not implemented")
+}
+func (UnimplementedCatServiceServer) mustEmbedUnimplementedCatServiceServer() {}
+
+// UnsafeCatServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CatServiceServer will
+// result in compilation errors.
+type UnsafeCatServiceServer interface {
+	mustEmbedUnimplementedCatServiceServer()
+}
+
+func RegisterCatServiceServer(s grpc.ServiceRegistrar, srv CatServiceServer) {
+	s.RegisterService(&CatService_ServiceDesc, srv)
+}
+
+func _CatService_GetImageAndFact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetImageAndFactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatServiceServer).GetImageAndFact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatService_GetImageAndFact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatServiceServer).GetImageAndFact(ctx, req.(*GetImageAndFactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatService_StreamCats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamCatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CatServiceServer).StreamCats(m, &catServiceStreamCatsServer{ServerStream: stream})
+}
+
+type CatService_StreamCatsServer interface {
+	Send(*CatResult) error
+	grpc.ServerStream
+}
+
+type catServiceStreamCatsServer struct {
+	grpc.ServerStream
+}
+
+func (x *catServiceStreamCatsServer) Send(m *CatResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CatService_BatchGetImageAndFact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetImageAndFactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatServiceServer).BatchGetImageAndFact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatService_BatchGetImageAndFact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatServiceServer).BatchGetImageAndFact(ctx, req.(*BatchGetImageAndFactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CatService_ServiceDesc is the grpc.ServiceDesc for CatService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CatService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cat.v1.CatService",
+	HandlerType: (*CatServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetImageAndFact",
+			Handler:    _CatService_GetImageAndFact_Handler,
+		},
+		{
+			MethodName: "BatchGetImageAndFact",
+			Handler:    _CatService_BatchGetImageAndFact_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamCats",
+			Handler:       _CatService_StreamCats_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "transport/grpc/catpb/cat.proto",
+}
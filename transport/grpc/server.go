@@ -0,0 +1,159 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/transport/grpc/catpb"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// MaxBatchSize is the largest count BatchGetImageAndFact will accept.
+const MaxBatchSize = 50
+
+// MaxStreamCount is the largest count StreamCats will accept. Zero still
+// streams until the client cancels.
+const MaxStreamCount = 1000
+
+// MinStreamInterval is the shortest pause StreamCats leaves between
+// results, so a stream can't call the upstreams in a tight loop.
+const MinStreamInterval = time.Second
+
+type Server struct {
+	catpb.UnimplementedCatServiceServer
+	c cat.Servicer
+}
+
+func NewServer(c cat.Servicer) (*Server, error) {
+	if c == nil {
+		return nil, errors.New("nil servicer")
+	}
+	return &Server{c: c}, nil
+}
+
+// Register creates a *grpc.Server with the CatService registered on it.
+func Register(s *Server, opts ...grpc.ServerOption) *grpc.Server {
+	g := grpc.NewServer(opts...)
+	catpb.RegisterCatServiceServer(g, s)
+	return g
+}
+
+func (s *Server) GetImageAndFact(ctx context.Context, _ *catpb.GetImageAndFactRequest) (*catpb.CatResult, error) {
+	c, err := s.c.GetImageAndFact(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(c), nil
+}
+
+func (s *Server) StreamCats(req *catpb.StreamCatsRequest, stream catpb.CatService_StreamCatsServer) error {
+	if req.GetCount() > MaxStreamCount {
+		return status.Errorf(codes.InvalidArgument, "count must be at most %d", MaxStreamCount)
+	}
+	ctx := stream.Context()
+	interval := max(time.Duration(req.GetIntervalMs())*time.Millisecond, MinStreamInterval)
+
+	for sent := uint32(0); req.GetCount() == 0 || sent < req.GetCount(); sent++ {
+		if sent > 0 {
+			t := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return toStatus(ctx.Err())
+			case <-t.C:
+			}
+		}
+
+		c, err := s.c.GetImageAndFact(ctx)
+		if err != nil {
+			return toStatus(err)
+		}
+		if err := stream.Send(toProto(c)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) BatchGetImageAndFact(ctx context.Context, req *catpb.BatchGetImageAndFactRequest) (*catpb.BatchGetImageAndFactResponse, error) {
+	if req.GetCount() == 0 || req.GetCount() > MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d", MaxBatchSize)
+	}
+
+	results := make([]*catpb.CatResult, req.GetCount())
+	eg, ctx := errgroup.WithContext(ctx)
+	for i := range results {
+		i := i
+		eg.Go(func() error {
+			c, err := s.c.GetImageAndFact(ctx)
+			if err != nil {
+				return err
+			}
+			results[i] = toProto(c)
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &catpb.BatchGetImageAndFactResponse{Results: results}, nil
+}
+
+func toProto(c cat.CatResult) *catpb.CatResult {
+	res := &catpb.CatResult{
+		Id:       c.ID,
+		ImageUrl: string(c.ImageURL),
+		Fact:     string(c.Fact),
+	}
+	if i := c.Image; i != nil {
+		res.Image = &catpb.Image{
+			Id:       i.ID,
+			Url:      string(i.URL),
+			Width:    uint32(i.Width),
+			Height:   uint32(i.Height),
+			MimeType: i.MIMEType,
+		}
+		for _, b := range i.Breeds {
+			res.Image.Breeds = append(res.Image.Breeds, &catpb.Breed{
+				Id:          b.ID,
+				Name:        b.Name,
+				Origin:      b.Origin,
+				Temperament: b.Temperament,
+			})
+		}
+	}
+	return res
+}
+
+// toStatus maps errors returned by the cat package onto gRPC status codes.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	var np cat.ErrNilParam
+	if errors.As(err, &np) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	var qe cat.ErrQuotaExhausted
+	if errors.As(err, &qe) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	var se cat.ErrServiceError
+	if errors.As(err, &se) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
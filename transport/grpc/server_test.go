@@ -0,0 +1,240 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/mock/mockcat"
+	catgrpc "github.com/matthewjamesboyle/catserver/transport/grpc"
+	"github.com/matthewjamesboyle/catserver/transport/grpc/catpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func newClient(t *testing.T, s cat.Servicer, opts ...grpc.ServerOption) catpb.CatServiceClient {
+	t.Helper()
+
+	srv, err := catgrpc.NewServer(s)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	g := catgrpc.Register(srv, opts...)
+	go func() { _ = g.Serve(lis) }()
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return catpb.NewCatServiceClient(conn)
+}
+
+func TestNewServer(t *testing.T) {
+	t.Run("returns an error given a nil servicer", func(t *testing.T) {
+		s, err := catgrpc.NewServer(nil)
+
+		assert.Nil(t, s)
+		assert.Error(t, err)
+	})
+
+	t.Run("Returns a Server", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s, err := catgrpc.NewServer(mockcat.NewMockServicer(ctrl))
+
+		assert.NoError(t, err)
+		assert.NotNil(t, s)
+	})
+}
+
+func TestServer_GetImageAndFact(t *testing.T) {
+	t.Run("Returns a CatResult", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{
+			ImageURL: "http://someurl",
+			Image: &cat.Image{
+				ID:       "abc",
+				URL:      "http://someurl",
+				Width:    640,
+				MIMEType: "image/png",
+				Breeds:   []cat.Breed{{ID: "beng", Name: "Bengal"}},
+			},
+			Fact: "some-fact",
+		}, nil)
+
+		res, err := newClient(t, s).GetImageAndFact(context.Background(), &catpb.GetImageAndFactRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://someurl", res.GetImageUrl())
+		assert.Equal(t, "some-fact", res.GetFact())
+		assert.Equal(t, "abc", res.GetImage().GetId())
+		assert.Equal(t, uint32(640), res.GetImage().GetWidth())
+		assert.Equal(t, "image/png", res.GetImage().GetMimeType())
+		require.Len(t, res.GetImage().GetBreeds(), 1)
+		assert.Equal(t, "Bengal", res.GetImage().GetBreeds()[0].GetName())
+	})
+
+	t.Run("Returns Unavailable given a service error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{}, cat.ErrServiceError{UnderLyingError: errors.New("some-error")})
+
+		_, err := newClient(t, s).GetImageAndFact(context.Background(), &catpb.GetImageAndFactRequest{})
+
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("Returns ResourceExhausted given an exhausted quota", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{}, cat.ErrServiceError{
+			UnderLyingError: cat.ErrQuotaExhausted{Upstream: "image", Period: "day", RetryAfter: time.Hour},
+		})
+
+		_, err := newClient(t, s).GetImageAndFact(context.Background(), &catpb.GetImageAndFactRequest{})
+
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("Returns Internal given an unknown error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{}, errors.New("some-error"))
+
+		_, err := newClient(t, s).GetImageAndFact(context.Background(), &catpb.GetImageAndFactRequest{})
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestServer_StreamCats(t *testing.T) {
+	t.Run("Sends count results then closes the stream", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{Fact: "some-fact"}, nil).Times(3)
+
+		stream, err := newClient(t, s).StreamCats(context.Background(), &catpb.StreamCatsRequest{Count: 3})
+		require.NoError(t, err)
+
+		var got int
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "some-fact", res.GetFact())
+			got++
+		}
+		assert.Equal(t, 3, got)
+	})
+
+	t.Run("Returns an error given the servicer fails mid stream", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		gomock.InOrder(
+			s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{Fact: "some-fact"}, nil),
+			s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{}, cat.ErrServiceError{UnderLyingError: errors.New("some-error")}),
+		)
+
+		stream, err := newClient(t, s).StreamCats(context.Background(), &catpb.StreamCatsRequest{Count: 5})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestServer_StreamCats_Limits(t *testing.T) {
+	t.Run("Returns InvalidArgument given a count over the maximum", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stream, err := newClient(t, mockcat.NewMockServicer(ctrl)).StreamCats(context.Background(), &catpb.StreamCatsRequest{Count: catgrpc.MaxStreamCount + 1})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Paces an unbounded stream with no interval", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{Fact: "some-fact"}, nil).MaxTimes(2)
+
+		ctx, cancel := context.WithTimeout(context.Background(), catgrpc.MinStreamInterval/2)
+		defer cancel()
+		stream, err := newClient(t, s).StreamCats(ctx, &catpb.StreamCatsRequest{})
+		require.NoError(t, err)
+
+		var got int
+		for {
+			if _, err := stream.Recv(); err != nil {
+				break
+			}
+			got++
+		}
+		assert.Equal(t, 1, got)
+	})
+}
+
+func TestServer_BatchGetImageAndFact(t *testing.T) {
+	t.Run("Returns count results", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{Fact: "some-fact"}, nil).Times(4)
+
+		res, err := newClient(t, s).BatchGetImageAndFact(context.Background(), &catpb.BatchGetImageAndFactRequest{Count: 4})
+
+		require.NoError(t, err)
+		assert.Len(t, res.GetResults(), 4)
+	})
+
+	t.Run("Returns InvalidArgument given a count out of range", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		c := newClient(t, mockcat.NewMockServicer(ctrl))
+
+		_, err := c.BatchGetImageAndFact(context.Background(), &catpb.BatchGetImageAndFactRequest{Count: 0})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = c.BatchGetImageAndFact(context.Background(), &catpb.BatchGetImageAndFactRequest{Count: catgrpc.MaxBatchSize + 1})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
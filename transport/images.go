@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"net/http"
+	"strconv"
+)
+
+type ImagesHandler struct {
+	c      *cat.ImageCache
+	stored ImageStore
+}
+
+// ImageStore keeps images beyond the ImageCache, by the same IDs. It is
+// satisfied by *permalink.BoundedStore.
+type ImageStore interface {
+	Image(id string) (data []byte, mimeType string, ok bool)
+}
+
+type ImagesHandlerOption func(h *ImagesHandler)
+
+// WithStoredImages serves images from s once the cache has dropped them, so
+// links that outlive the cache, like permalinks, keep working.
+func WithStoredImages(s ImageStore) ImagesHandlerOption {
+	return func(h *ImagesHandler) {
+		h.stored = s
+	}
+}
+
+func NewImagesHandler(c *cat.ImageCache, opts ...ImagesHandlerOption) (*ImagesHandler, error) {
+	if c == nil {
+		return nil, errors.New("nil image cache")
+	}
+	h := &ImagesHandler{c: c}
+	for _, o := range opts {
+		o(h)
+	}
+	return h, nil
+}
+
+// Get serves an image kept from an upstream that returns images inline.
+func (h ImagesHandler) Get(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	b, mt, ok := h.c.Get(id)
+	if !ok && h.stored != nil {
+		b, mt, ok = h.stored.Image(id)
+	}
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	// IDs are derived from the bytes, so an image never changes.
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("Content-Type", mt)
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	_, _ = w.Write(b)
+}
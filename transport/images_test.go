@@ -0,0 +1,68 @@
+package transport_test
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewImagesHandler(t *testing.T) {
+	h, err := transport.NewImagesHandler(nil)
+
+	assert.Nil(t, h)
+	assert.Error(t, err)
+}
+
+func TestImagesHandler_Get(t *testing.T) {
+	c, err := cat.NewImageCache(cat.MaxBinaryImageSize)
+	require.NoError(t, err)
+	id := c.Put([]byte("some-image"), "image/png")
+
+	h, err := transport.NewImagesHandler(c)
+	require.NoError(t, err)
+	r := mux.NewRouter()
+	r.HandleFunc("/images/{id}", h.Get)
+
+	t.Run("Serves a cached image", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/images/"+id, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "some-image", rr.Body.String())
+	})
+
+	t.Run("Returns 404 given an unknown image", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/images/unknown", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestImagesHandler_Get_StoredImages(t *testing.T) {
+	c, err := cat.NewImageCache(cat.MaxBinaryImageSize)
+	require.NoError(t, err)
+	h, err := transport.NewImagesHandler(c, transport.WithStoredImages(storedImages{"some-id": "some-image"}))
+	require.NoError(t, err)
+	r := mux.NewRouter()
+	r.HandleFunc("/images/{id}", h.Get)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/images/some-id", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "some-image", rr.Body.String())
+}
+
+type storedImages map[string]string
+
+func (s storedImages) Image(id string) ([]byte, string, bool) {
+	b, ok := s[id]
+	return []byte(b), "image/png", ok
+}
@@ -0,0 +1,157 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
+	"github.com/matthewjamesboyle/catserver/internal/permalink"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var permalinkPage = template.Must(template.New("permalink").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Cat fact</title>
+<meta property="og:type" content="article">
+<meta property="og:title" content="Cat fact">
+<meta property="og:description" content="{{.Fact}}">
+<meta property="og:image" content="{{.ImageURL}}">
+{{with .Image}}{{if .Width}}<meta property="og:image:width" content="{{.Width}}">
+<meta property="og:image:height" content="{{.Height}}">
+{{end}}{{if .MIMEType}}<meta property="og:image:type" content="{{.MIMEType}}">
+{{end}}{{end}}<meta property="og:image:alt" content="{{.Alt}}">
+<meta property="og:url" content="{{.URL}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:title" content="Cat fact">
+<meta name="twitter:description" content="{{.Fact}}">
+<meta name="twitter:image" content="{{.ImageURL}}">
+</head>
+<body>
+<img src="{{.ImageURL}}" alt="{{.Alt}}">
+<p>{{.Fact}}</p>
+</body>
+</html>
+`))
+
+type PermalinkHandler struct {
+	s permalink.Store
+	// baseURL is used for og:url, and to make relative image URLs absolute
+	// as cards need. If empty it is built from the request.
+	baseURL    string
+	translator cat.ResultTranslator
+}
+
+type PermalinkHandlerOption func(h *PermalinkHandler)
+
+// WithPermalinkTranslator translates stored results for each caller.
+func WithPermalinkTranslator(t cat.ResultTranslator) PermalinkHandlerOption {
+	return func(h *PermalinkHandler) {
+		h.translator = t
+	}
+}
+
+func NewPermalinkHandler(s permalink.Store, baseURL string, opts ...PermalinkHandlerOption) (*PermalinkHandler, error) {
+	if s == nil {
+		return nil, errors.New("nil permalink store")
+	}
+	if baseURL != "" {
+		u, err := url.Parse(baseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("base url %q must be absolute", baseURL)
+		}
+	}
+	h := &PermalinkHandler{s: s, baseURL: strings.TrimSuffix(baseURL, "/")}
+	for _, o := range opts {
+		o(h)
+	}
+	return h, nil
+}
+
+func (h PermalinkHandler) Get(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if !permalink.ValidID(id) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	c, err := h.s.Get(req.Context(), id)
+	if errors.Is(err, permalink.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logging.FromContext(req.Context()).Error("getting permalink", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Results never change once stored, but are translated per caller.
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("Vary", "Accept, Accept-Language")
+	if h.translator != nil {
+		c = h.translator.TranslateResult(req.Context(), c)
+	}
+
+	if !wantsHTML(req) {
+		writeJSON(w, http.StatusOK, c)
+		return
+	}
+
+	base := h.baseURL
+	if base == "" {
+		scheme := "http"
+		if req.TLS != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + req.Host
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	alt := "A cat"
+	if c.Image != nil && len(c.Image.Breeds) > 0 {
+		alt = "A " + c.Image.Breeds[0].Name + " cat"
+	}
+	_ = permalinkPage.Execute(w, struct {
+		Fact, ImageURL, URL, Alt string
+		Image                    *cat.Image
+	}{
+		Fact:     string(c.Fact),
+		ImageURL: absolute(base, string(c.ImageURL)),
+		URL:      base + "/c/" + c.ID,
+		Alt:      alt,
+		Image:    c.Image,
+	})
+}
+
+// absolute resolves ref, e.g. an image served by this server at /images/{id},
+// against base. Unfurlers ignore relative og:image URLs.
+func absolute(base, ref string) string {
+	b, err := url.Parse(base + "/")
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// wantsHTML picks HTML for browsers and link unfurlers, which ask for
+// text/html, unless ?format= says otherwise.
+func wantsHTML(req *http.Request) bool {
+	switch req.URL.Query().Get("format") {
+	case "html":
+		return true
+	case "json":
+		return false
+	}
+	return strings.Contains(req.Header.Get("Accept"), "text/html")
+}
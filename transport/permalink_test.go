@@ -0,0 +1,115 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/permalink"
+	"github.com/matthewjamesboyle/catserver/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewPermalinkHandler(t *testing.T) {
+	t.Run("Returns an error given a nil store", func(t *testing.T) {
+		h, err := transport.NewPermalinkHandler(nil, "")
+
+		assert.Nil(t, h)
+		assert.Error(t, err)
+	})
+
+	t.Run("Returns an error given a relative base url", func(t *testing.T) {
+		store, err := permalink.NewBoundedStore("", 10)
+		require.NoError(t, err)
+
+		h, err := transport.NewPermalinkHandler(store, "cats.example")
+
+		assert.Nil(t, h)
+		assert.Error(t, err)
+	})
+}
+
+func TestPermalinkHandler_Get(t *testing.T) {
+	c := cat.CatResult{
+		ImageURL: "https://cdn.example/cat.jpg",
+		Image: &cat.Image{
+			URL:      "https://cdn.example/cat.jpg",
+			Width:    640,
+			Height:   480,
+			MIMEType: "image/jpeg",
+			Breeds:   []cat.Breed{{ID: "beng", Name: "Bengal"}},
+		},
+		Fact: "Cats <3 boxes",
+	}
+	c.ID = permalink.ID(c)
+
+	store, err := permalink.NewBoundedStore("", 10)
+	require.NoError(t, err)
+	require.NoError(t, store.Put(context.Background(), c))
+
+	h, err := transport.NewPermalinkHandler(store, "https://cats.example/")
+	require.NoError(t, err)
+	r := mux.NewRouter()
+	r.HandleFunc("/c/{id}", h.Get)
+
+	t.Run("Returns JSON by default", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/c/"+c.ID, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got cat.CatResult
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+		assert.Equal(t, c, got)
+	})
+
+	t.Run("Returns HTML with card meta tags to browsers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/c/"+c.ID, nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		body := rr.Body.String()
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, body, `<meta property="og:image" content="https://cdn.example/cat.jpg">`)
+		assert.Contains(t, body, `<meta property="og:image:width" content="640">`)
+		assert.Contains(t, body, `<meta property="og:image:type" content="image/jpeg">`)
+		assert.Contains(t, body, `alt="A Bengal cat"`)
+		assert.Contains(t, body, `<meta property="og:url" content="https://cats.example/c/`+c.ID+`">`)
+		assert.Contains(t, body, `<meta name="twitter:card" content="summary_large_image">`)
+		assert.Contains(t, body, `Cats &lt;3 boxes`)
+	})
+
+	t.Run("Honours the format parameter", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/c/"+c.ID+"?format=html", nil))
+
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+	})
+
+	t.Run("Returns 404 for an unknown ID", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/c/aaaaaaaaaa", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+	t.Run("Makes an image served by this server absolute", func(t *testing.T) {
+		c := cat.CatResult{ImageURL: "/images/abc123", Fact: "some-fact"}
+		c.ID = permalink.ID(c)
+		require.NoError(t, store.Put(context.Background(), c))
+		h, err := transport.NewPermalinkHandler(store, "")
+		require.NoError(t, err)
+		r := mux.NewRouter()
+		r.HandleFunc("/c/{id}", h.Get)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://cats.example/c/"+c.ID+"?format=html", nil))
+
+		assert.Contains(t, rr.Body.String(), `<meta property="og:image" content="http://cats.example/images/abc123">`)
+		assert.Contains(t, rr.Body.String(), `<meta name="twitter:image" content="http://cats.example/images/abc123">`)
+	})
+}
@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/cat"
+	"github.com/matthewjamesboyle/catserver/internal/problem"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// writeQuotaProblem answers with a 503 and Retry-After if err is an upstream
+// being over its quota, which says nothing about this server's health, and
+// reports whether it did.
+func writeQuotaProblem(w http.ResponseWriter, err error) bool {
+	var qe cat.ErrQuotaExhausted
+	if !errors.As(err, &qe) {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(qe.RetryAfter.Seconds()))))
+	problem.Write(w, problem.Problem{
+		Type:   "/problems/quota-exhausted",
+		Status: http.StatusServiceUnavailable,
+		Detail: "the " + qe.Upstream + " upstream is over its quota for the " + qe.Period,
+	})
+	return true
+}
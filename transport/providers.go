@@ -0,0 +1,30 @@
+package transport
+
+import (
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/routing"
+	"net/http"
+)
+
+type ProvidersHandler struct {
+	routers map[string]*routing.Router
+}
+
+// NewProvidersHandler reports on routers, keyed by what they route, e.g.
+// "fact" or "image".
+func NewProvidersHandler(routers map[string]*routing.Router) (*ProvidersHandler, error) {
+	if len(routers) == 0 {
+		return nil, errors.New("no routers")
+	}
+	return &ProvidersHandler{routers: routers}, nil
+}
+
+// Scores returns each router's providers, best first.
+func (h ProvidersHandler) Scores(w http.ResponseWriter, _ *http.Request) {
+	res := make(map[string][]routing.Score, len(h.routers))
+	for name, r := range h.routers {
+		res[name] = r.Scores()
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,41 @@
+package transport_test
+
+import (
+	"encoding/json"
+	"github.com/matthewjamesboyle/catserver/internal/routing"
+	"github.com/matthewjamesboyle/catserver/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewProvidersHandler(t *testing.T) {
+	h, err := transport.NewProvidersHandler(nil)
+
+	assert.Nil(t, h)
+	assert.Error(t, err)
+}
+
+func TestProvidersHandler_Scores(t *testing.T) {
+	r, err := routing.NewRouter([]string{"slow", "fast"})
+	require.NoError(t, err)
+	r.Observe("slow", time.Second, nil)
+	r.Observe("fast", 120*time.Millisecond, nil)
+
+	h, err := transport.NewProvidersHandler(map[string]*routing.Router{"fact": r})
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	h.Scores(rr, httptest.NewRequest(http.MethodGet, "/admin/providers", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res map[string][]map[string]interface{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&res))
+	require.Len(t, res["fact"], 2)
+	assert.Equal(t, "fast", res["fact"][0]["name"])
+	assert.Equal(t, "120ms", res["fact"][0]["latency"])
+	assert.Equal(t, true, res["fact"][0]["best"])
+}
@@ -0,0 +1,116 @@
+package transport
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/matthewjamesboyle/catserver/internal/problem"
+	"github.com/matthewjamesboyle/catserver/internal/search"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+)
+
+type SearchHandler struct {
+	idx *search.Index
+}
+
+func NewSearchHandler(idx *search.Index) (*SearchHandler, error) {
+	if idx == nil {
+		return nil, errors.New("nil search index")
+	}
+	return &SearchHandler{idx: idx}, nil
+}
+
+type searchPage struct {
+	Total      int          `json:"total"`
+	Results    []search.Hit `json:"results"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// Search ranks known facts against ?q=. Results are paged like favorites: the
+// cursor is opaque to callers and comes from the previous page. It is only
+// good for as long as no fact is added to the index; after that, paging has
+// to start again.
+func (h SearchHandler) Search(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	limit := defaultSearchLimit
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+	var c searchCursor
+	if raw := q.Get("cursor"); raw != "" {
+		var err error
+		if c, err = parseSearchCursor(raw); err != nil {
+			problem.Write(w, problem.Problem{
+				Type:   "/problems/invalid-cursor",
+				Status: http.StatusBadRequest,
+				Detail: "cursor is not one from a previous page",
+				Param:  "cursor",
+				Value:  raw,
+			})
+			return
+		}
+	}
+
+	hits, total, gen, err := h.idx.SearchPage(q.Get("q"), c.gen, c.offset, limit)
+	if errors.Is(err, search.ErrIndexChanged) {
+		problem.Write(w, problem.Problem{
+			Type:   "/problems/stale-cursor",
+			Status: http.StatusConflict,
+			Detail: "facts were added since the first page, search again without a cursor",
+			Param:  "cursor",
+		})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	page := searchPage{Total: total, Results: hits}
+	if next := c.offset + len(hits); next < total {
+		page.NextCursor = searchCursor{gen: gen, offset: next}.String()
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// searchCursor is where the next page starts in the ranking of the index at
+// generation gen.
+type searchCursor struct {
+	gen    uint64
+	offset int
+}
+
+func (c searchCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(c.gen, 10) + ":" + strconv.Itoa(c.offset)))
+}
+
+func parseSearchCursor(s string) (searchCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return searchCursor{}, err
+	}
+	gen, offset, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return searchCursor{}, errors.New("malformed cursor")
+	}
+	var c searchCursor
+	if c.gen, err = strconv.ParseUint(gen, 10, 64); err != nil || c.gen == 0 {
+		return searchCursor{}, errors.New("malformed cursor")
+	}
+	if c.offset, err = strconv.Atoi(offset); err != nil || c.offset < 0 {
+		return searchCursor{}, errors.New("malformed cursor")
+	}
+	return c, nil
+}
@@ -0,0 +1,91 @@
+package transport_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/matthewjamesboyle/catserver/internal/search"
+	"github.com/matthewjamesboyle/catserver/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewSearchHandler(t *testing.T) {
+	h, err := transport.NewSearchHandler(nil)
+
+	assert.Nil(t, h)
+	assert.Error(t, err)
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	idx := search.NewIndex(0)
+	idx.Add("Cats have whiskers.")
+	idx.Add("Whiskers help cats balance.")
+	idx.Add("Dogs drool.")
+	h, err := transport.NewSearchHandler(idx)
+	require.NoError(t, err)
+
+	type page struct {
+		Total   int `json:"total"`
+		Results []struct {
+			Fact string `json:"fact"`
+		} `json:"results"`
+		NextCursor string `json:"next_cursor"`
+	}
+	get := func(t *testing.T, target string) (*httptest.ResponseRecorder, page) {
+		rr := httptest.NewRecorder()
+		h.Search(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		var p page
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&p))
+		}
+		return rr, p
+	}
+
+	t.Run("Pages through results", func(t *testing.T) {
+		rr, p := get(t, "/facts/search?q=whiskers&limit=1")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 2, p.Total)
+		require.Len(t, p.Results, 1)
+		require.NotEmpty(t, p.NextCursor)
+		require.NotEqual(t, "1", p.NextCursor, "the cursor is opaque")
+
+		_, p2 := get(t, "/facts/search?q=whiskers&limit=1&cursor="+p.NextCursor)
+		require.Len(t, p2.Results, 1)
+		assert.NotEqual(t, p.Results[0].Fact, p2.Results[0].Fact)
+		assert.Empty(t, p2.NextCursor)
+	})
+
+	t.Run("Rejects a cursor from before the index changed", func(t *testing.T) {
+		_, p := get(t, "/facts/search?q=whiskers&limit=1")
+		require.NotEmpty(t, p.NextCursor)
+		idx.Add("Cats' whiskers sense air currents.")
+
+		rr, _ := get(t, "/facts/search?q=whiskers&limit=1&cursor="+p.NextCursor)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), "/problems/stale-cursor")
+	})
+
+	t.Run("Returns an empty list with no matches", func(t *testing.T) {
+		rr, p := get(t, "/facts/search?q=fish")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 0, p.Total)
+		assert.Empty(t, p.Results)
+	})
+
+	for name, target := range map[string]string{
+		"missing query": "/facts/search",
+		"stop words":    "/facts/search?q=the",
+		"bad limit":     "/facts/search?q=cats&limit=0",
+		"bad cursor":    "/facts/search?q=cats&cursor=x",
+		"plain offset":  "/facts/search?q=cats&cursor=1",
+		"bad offset":    "/facts/search?q=cats&cursor=" + base64.RawURLEncoding.EncodeToString([]byte("1:-1")),
+	} {
+		t.Run("Rejects "+name, func(t *testing.T) {
+			rr, _ := get(t, target)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
@@ -3,27 +3,49 @@ package transport
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/matthewjamesboyle/catserver/internal/cat"
-	"log"
+	"github.com/matthewjamesboyle/catserver/internal/logging"
 	"net/http"
 )
 
 type HttpHandler struct {
-	c cat.Servicer
+	c      cat.Servicer
+	breeds cat.BreedLister
 }
 
-func NewHttpHandler(c cat.Servicer) (*HttpHandler, error) {
+type HttpHandlerOption func(h *HttpHandler)
+
+// WithBreeds makes Get reject ?breed= values l doesn't list.
+func WithBreeds(l cat.BreedLister) HttpHandlerOption {
+	return func(h *HttpHandler) {
+		h.breeds = l
+	}
+}
+
+func NewHttpHandler(c cat.Servicer, opts ...HttpHandlerOption) (*HttpHandler, error) {
 	if c == nil {
 		return nil, errors.New("nil servicer")
 	}
-	return &HttpHandler{c: c}, nil
+	h := &HttpHandler{c: c}
+	for _, o := range opts {
+		o(h)
+	}
+	return h, nil
 }
 
 func (h HttpHandler) Get(w http.ResponseWriter, req *http.Request) {
-	c, err := h.c.GetImageAndFact(req.Context())
+	f, err := parseImageFilter(req, h.breeds)
+	if err != nil {
+		writeFilterProblem(w, err)
+		return
+	}
+
+	c, err := h.c.GetImageAndFact(cat.WithImageFilter(req.Context(), f))
+	if writeQuotaProblem(w, err) {
+		return
+	}
 	if err != nil {
-		log.Println(fmt.Errorf("error: %w", err))
+		logging.FromContext(req.Context()).Error("GetImageAndFact failed", "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestNewHttpHandler(t *testing.T) {
@@ -54,6 +55,26 @@ func TestHttpHandler_Get(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 	})
 
+	t.Run("Returns a 503 with Retry-After given an exhausted quota", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		h, err := transport.NewHttpHandler(s)
+		require.NoError(t, err)
+
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{}, cat.ErrServiceError{
+			UnderLyingError: cat.ErrQuotaExhausted{Upstream: "image", Period: "day", RetryAfter: 90500 * time.Millisecond},
+		})
+
+		rr := httptest.NewRecorder()
+		h.Get(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Equal(t, "91", rr.Header().Get("Retry-After"))
+		assert.Contains(t, rr.Body.String(), "/problems/quota-exhausted")
+	})
+
 	t.Run("Returns a 200 and a catResult", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -83,4 +104,79 @@ func TestHttpHandler_Get(t *testing.T) {
 		assert.Equal(t, someFact.Fact, res.Fact)
 	})
 
+	t.Run("Passes a valid filter to the servicer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		b := mockcat.NewMockBreedLister(ctrl)
+		h, err := transport.NewHttpHandler(s, transport.WithBreeds(b))
+		require.NoError(t, err)
+
+		b.EXPECT().ListBreeds(gomock.Any()).Return([]cat.Breed{{ID: "beng", Name: "Bengal"}}, nil)
+		s.EXPECT().GetImageAndFact(gomock.Any()).DoAndReturn(func(ctx context.Context) (cat.CatResult, error) {
+			assert.Equal(t, cat.ImageFilter{
+				Breed:     "beng",
+				Category:  2,
+				MIMETypes: []string{"gif", "png"},
+				Size:      "small",
+			}, cat.ImageFilterFromContext(ctx))
+			return cat.CatResult{}, nil
+		})
+
+		rr := httptest.NewRecorder()
+		h.Get(rr, httptest.NewRequest(http.MethodGet, "/?breed=beng&category=2&mime_types=gif,png&size=small", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Lets breeds through when they can't be listed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := mockcat.NewMockServicer(ctrl)
+		b := mockcat.NewMockBreedLister(ctrl)
+		h, err := transport.NewHttpHandler(s, transport.WithBreeds(b))
+		require.NoError(t, err)
+
+		b.EXPECT().ListBreeds(gomock.Any()).Return(nil, errors.New("some-error"))
+		s.EXPECT().GetImageAndFact(gomock.Any()).Return(cat.CatResult{}, nil)
+
+		rr := httptest.NewRecorder()
+		h.Get(rr, httptest.NewRequest(http.MethodGet, "/?breed=beng", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	for name, tc := range map[string]struct {
+		target, problemType, param string
+	}{
+		"unknown breed": {"/?breed=dog", "/problems/unknown-breed", "breed"},
+		"bad size":      {"/?size=huge", "/problems/invalid-filter", "size"},
+		"bad mime type": {"/?mime_types=gif,bmp", "/problems/invalid-filter", "mime_types"},
+		"bad category":  {"/?category=hats", "/problems/invalid-filter", "category"},
+	} {
+		t.Run("Returns a 400 problem given a "+name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			b := mockcat.NewMockBreedLister(ctrl)
+			b.EXPECT().ListBreeds(gomock.Any()).Return([]cat.Breed{{ID: "beng"}}, nil).AnyTimes()
+			h, err := transport.NewHttpHandler(mockcat.NewMockServicer(ctrl), transport.WithBreeds(b))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			h.Get(rr, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+			var p struct {
+				Type  string `json:"type"`
+				Param string `json:"param"`
+			}
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&p))
+			assert.Equal(t, tc.problemType, p.Type)
+			assert.Equal(t, tc.param, p.Param)
+		})
+	}
 }